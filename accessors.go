@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// The accessors below convert values with the same rules Populate uses,
+// e.g. v.Duration() and populating a time.Duration field with v never disagree.
+// They return an error if the value is missing or can't be converted.
+
+// Duration returns the value as a time.Duration, e.g. "1m30s".
+func (cv Value) Duration() (time.Duration, error) {
+	var res time.Duration
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// Time returns the value as a time.Time. Strings are parsed with the layouts,
+// the RFC 3339 format is used if no layouts are provided.
+func (cv Value) Time(layouts ...string) (time.Time, error) {
+	var res time.Time
+	if len(layouts) == 0 {
+		err := cv.populateDefined(&res)
+		return res, err
+	}
+
+	if err := cv.defined(); err != nil {
+		return res, err
+	}
+
+	if t, ok := cv.Value().(time.Time); ok {
+		return t, nil
+	}
+
+	s := cv.String()
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return res, fmt.Errorf("for key %q: time %q doesn't match layouts %q", cv.key, s, layouts)
+}
+
+// ByteSize returns the value as a number of bytes, e.g. "512MiB".
+func (cv Value) ByteSize() (ByteSize, error) {
+	var res ByteSize
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// Percent returns the value as a fraction, e.g. 0.75 for "75%".
+func (cv Value) Percent() (Percent, error) {
+	var res Percent
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// URL returns the value as a parsed URL.
+func (cv Value) URL() (*url.URL, error) {
+	var res url.URL
+	if err := cv.populateDefined(&res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// IP returns the value as an IP address.
+func (cv Value) IP() (net.IP, error) {
+	var res net.IP
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// CIDR returns the value as an IP network, e.g. "10.0.0.0/8".
+func (cv Value) CIDR() (*net.IPNet, error) {
+	var res net.IPNet
+	if err := cv.populateDefined(&res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Regexp returns the value as a compiled regular expression.
+func (cv Value) Regexp() (*regexp.Regexp, error) {
+	var res *regexp.Regexp
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// Strings returns a sequence value as a slice of strings.
+func (cv Value) Strings() ([]string, error) {
+	var res []string
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+// StringMap returns a map value as a map of strings.
+func (cv Value) StringMap() (map[string]string, error) {
+	var res map[string]string
+	err := cv.populateDefined(&res)
+	return res, err
+}
+
+func (cv Value) defined() error {
+	if !cv.HasValue() {
+		return fmt.Errorf("value for the key %q is not defined", cv.key)
+	}
+
+	return nil
+}
+
+func (cv Value) populateDefined(target interface{}) error {
+	if err := cv.defined(); err != nil {
+		return err
+	}
+
+	return cv.Populate(target)
+}
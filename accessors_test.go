@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var accessorsYaml = []byte(`
+timeout: 1m30s
+created: 2017-06-06T10:00:00Z
+date: 06/06/2017
+cache: 512MiB
+disk: 1.5GB
+memory: 1024
+ratio: 75%
+fraction: 0.25
+endpoint: https://gotham.example.com:8080/api?v=2
+bad_endpoint: "%zz"
+ip: 10.0.0.1
+network: 10.0.0.0/8
+pattern: ^bat(man|mobile)$
+heroes: [batman, robin, 42]
+owners: {batman: bruce, robin: dick}
+`)
+
+func TestValue_Accessors(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(accessorsYaml)
+
+	d, err := p.Get("timeout").Duration()
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	tm, err := p.Get("created").Time()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 6, 6, 10, 0, 0, 0, time.UTC), tm)
+
+	tm, err = p.Get("date").Time("2006-01-02", "01/02/2006")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 6, 6, 0, 0, 0, 0, time.UTC), tm)
+
+	b, err := p.Get("cache").ByteSize()
+	require.NoError(t, err)
+	assert.Equal(t, 512*MiB, b)
+
+	b, err = p.Get("disk").ByteSize()
+	require.NoError(t, err)
+	assert.Equal(t, ByteSize(1500000000), b)
+
+	b, err = p.Get("memory").ByteSize()
+	require.NoError(t, err)
+	assert.Equal(t, KiB, b)
+
+	pc, err := p.Get("ratio").Percent()
+	require.NoError(t, err)
+	assert.Equal(t, Percent(0.75), pc)
+
+	pc, err = p.Get("fraction").Percent()
+	require.NoError(t, err)
+	assert.Equal(t, Percent(0.25), pc)
+
+	u, err := p.Get("endpoint").URL()
+	require.NoError(t, err)
+	assert.Equal(t, "gotham.example.com:8080", u.Host)
+	assert.Equal(t, "2", u.Query().Get("v"))
+
+	ip, err := p.Get("ip").IP()
+	require.NoError(t, err)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), ip)
+
+	n, err := p.Get("network").CIDR()
+	require.NoError(t, err)
+	assert.True(t, n.Contains(ip))
+
+	re, err := p.Get("pattern").Regexp()
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("batmobile"))
+
+	heroes, err := p.Get("heroes").Strings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"batman", "robin", "42"}, heroes)
+
+	owners, err := p.Get("owners").StringMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"batman": "bruce", "robin": "dick"}, owners)
+}
+
+func TestValue_AccessorErrors(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(accessorsYaml)
+
+	_, err := p.Get("missing").Duration()
+	assert.EqualError(t, err, `value for the key "missing" is not defined`)
+
+	_, err = p.Get("ratio").Duration()
+	assert.Error(t, err)
+
+	_, err = p.Get("date").Time("2006-01-02")
+	assert.EqualError(t, err, `for key "date": time "06/06/2017" doesn't match layouts ["2006-01-02"]`)
+
+	_, err = p.Get("ratio").ByteSize()
+	assert.Error(t, err)
+
+	_, err = p.Get("bad_endpoint").URL()
+	assert.Error(t, err)
+
+	_, err = p.Get("timeout").IP()
+	assert.Error(t, err)
+
+	_, err = p.Get("ip").CIDR()
+	assert.Error(t, err)
+
+	_, err = NewStaticProvider(map[string]string{"pattern": "("}).Get("pattern").Regexp()
+	assert.Error(t, err)
+}
+
+func TestValue_AccessorsMatchPopulate(t *testing.T) {
+	t.Parallel()
+
+	var cfg struct {
+		Timeout  time.Duration  `yaml:"timeout"`
+		Created  time.Time      `yaml:"created"`
+		Cache    ByteSize       `yaml:"cache"`
+		Ratio    Percent        `yaml:"ratio"`
+		IP       net.IP         `yaml:"ip"`
+		Network  *net.IPNet     `yaml:"network"`
+		Endpoint *url.URL       `yaml:"endpoint"`
+		Pattern  *regexp.Regexp `yaml:"pattern"`
+	}
+
+	p := NewYAMLProviderFromBytes(accessorsYaml)
+	require.NoError(t, p.Get(Root).Populate(&cfg))
+
+	d, _ := p.Get("timeout").Duration()
+	assert.Equal(t, d, cfg.Timeout)
+	tm, _ := p.Get("created").Time()
+	assert.Equal(t, tm, cfg.Created)
+	b, _ := p.Get("cache").ByteSize()
+	assert.Equal(t, b, cfg.Cache)
+	pc, _ := p.Get("ratio").Percent()
+	assert.Equal(t, pc, cfg.Ratio)
+	ip, _ := p.Get("ip").IP()
+	assert.Equal(t, ip, cfg.IP)
+	n, _ := p.Get("network").CIDR()
+	assert.Equal(t, n, cfg.Network)
+	u, _ := p.Get("endpoint").URL()
+	assert.Equal(t, u, cfg.Endpoint)
+	re, _ := p.Get("pattern").Regexp()
+	assert.Equal(t, re.String(), cfg.Pattern.String())
+}
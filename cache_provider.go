@@ -63,7 +63,7 @@ func (p *cachedProvider) Get(key string) Value {
 	p.RUnlock()
 	err := p.Provider.RegisterChangeCallback(key, func(key string, provider string, data interface{}) {
 		p.Lock()
-		p.cache[key] = NewValue(p, key, data, true, GetType(data), nil)
+		p.cache[key] = newValue(p, key, data, true, GetType(data), newRevision())
 		p.Unlock()
 	})
 
@@ -80,6 +80,15 @@ func (p *cachedProvider) Get(key string) Value {
 	return v
 }
 
+func (p *cachedProvider) Keys(key string) []string {
+	return keysOf(p.Provider, key)
+}
+
+// Origins returns origins of the key in the underlying provider.
+func (p *cachedProvider) Origins(key string) []Origin {
+	return originsOf(p.Provider, key)
+}
+
 // No need to register a callback, all the values are fresh.
 func (p *cachedProvider) RegisterChangeCallback(key string, callback ChangeCallback) error {
 	return nil
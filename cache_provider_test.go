@@ -55,9 +55,11 @@ func TestCachedProvider_GetNewValues(t *testing.T) {
 	require.True(t, v.HasValue())
 	assert.Equal(t, "Simpsons", v.Value())
 
-	ts := v.LastUpdated()
+	ts, version := v.LastUpdated(), v.Get(Root).Version()
+	assert.NotZero(t, version, "cached changes should be versioned")
 	m.Set("cartoon", "Futurama")
 	assert.True(t, ts.Before(v.Get(Root).LastUpdated()))
+	assert.True(t, v.Get(Root).Version() > version)
 
 	assert.Equal(t, p, v.provider)
 }
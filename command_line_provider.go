@@ -85,7 +85,7 @@ func assignValues(m map[string]interface{}, key string, value flag.Value) {
 // Traverse map with the flag name used as path.
 func traversePath(m map[string]interface{}, f *flag.Flag) (map[string]interface{}, string) {
 	curr, prev := m, m
-	path := strings.Split(f.Name, _separator)
+	path := ParsePath(f.Name)
 	for _, item := range path {
 		if _, ok := curr[item]; !ok {
 			curr[item] = map[string]interface{}{}
@@ -107,3 +107,11 @@ func traversePath(m map[string]interface{}, f *flag.Flag) (map[string]interface{
 func (commandLineProvider) Name() string {
 	return "cmd"
 }
+
+func (c commandLineProvider) Keys(key string) []string {
+	return keysOf(c.Provider, key)
+}
+
+func (c commandLineProvider) Origins(key string) []Origin {
+	return generatedOrigins(originsOf(c.Provider, key), c.Name())
+}
@@ -43,6 +43,17 @@ func TestCommandLineProvider_Roles(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", `c"d"`}, roles)
 }
 
+func TestCommandLineProvider_Explain(t *testing.T) {
+	t.Parallel()
+
+	f := flag.NewFlagSet("", flag.PanicOnError)
+	f.String("hero", "", "")
+
+	e, err := Explain(NewCommandLineProvider(f, []string{"--hero=Batman"}), "hero")
+	require.NoError(t, err)
+	assert.Equal(t, Origin{Provider: "cmd", Value: "Batman"}, e.Origin, "positions in generated YAML are meaningless")
+}
+
 func TestCommandLineProvider_Default(t *testing.T) {
 	t.Parallel()
 
@@ -26,8 +26,10 @@ import (
 	"path"
 	"path/filepath"
 	"sync"
+	"text/template"
 
 	flag "github.com/ogier/pflag"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -46,6 +48,7 @@ const (
 	_appRoot     = "_ROOT"
 	_environment = "_ENVIRONMENT"
 	_configDir   = "_CONFIG_DIR"
+	_hostname    = "_HOSTNAME"
 	_baseFile    = "base.yaml"
 	_secretsFile = "secrets.yaml"
 	_devEnv      = "development"
@@ -60,6 +63,10 @@ type Loader struct {
 	envPrefix            string
 	staticProviderFuncs  []ProviderFunc
 	dynamicProviderFuncs []DynamicProviderFunc
+	validatorFuncs       []ValidatorFunc
+
+	// Provider returned by the last Load call, it is updated on Reload.
+	reloadable *Reloadable
 
 	// Files to load, they will be replaced with values from environment variables.
 	configFiles []string
@@ -70,8 +77,41 @@ type Loader struct {
 	// Dirs to load from.
 	dirs []string
 
+	// Find more dirs to load from after dirs.
+	dirFinders []DirFinder
+
+	// Dirs searched for files by the last Load or Reload call.
+	searched []string
+
+	// Environment to file name to whether the file is required.
+	requiredFiles map[string]map[string]bool
+
+	// Files loaded by the last successful Load or Reload call.
+	manifest Manifest
+
+	// Variables for interpolation, they take precedence over lookUp.
+	variables map[string]string
+
+	// Files with variables for interpolation, lookUp takes precedence over them.
+	envFiles []string
+
+	// Variables used by the last successful Load or Reload call.
+	variableReport VariableReport
+
+	// Functions for config file templates, templates are disabled if nil.
+	templateFuncs template.FuncMap
+
+	// Handlers for application specific YAML tags, DefaultTagHandlers are used if nil.
+	tagHandlers map[string]TagHandler
+
+	// Keys that differ by case are different keys.
+	caseSensitive bool
+
 	// Where to look for environment variables.
 	lookUp lookUpFunc
+
+	// Resolves files instead of a relative resolver for dirs, if set.
+	resolver FileResolver
 }
 
 // DefaultLoader is going to be used by a service if config is not specified.
@@ -95,12 +135,13 @@ func NewLoader(providers ...ProviderFunc) *Loader {
 }
 
 // TestConfig is Provider that can be used for testing. It loads configuration from
-// base.yaml and test.yaml files.
-var TestConfig = func() Provider {
+// base.yaml and test.yaml files on the first use. See the configtest package
+// for hermetic alternatives.
+var TestConfig = newLazyProvider(func() Provider {
 	l := NewLoader()
 	l.SetConfigFiles(_baseFile, "test.yaml")
 	return l.Load()
-}()
+})
 
 // AppRoot returns the root directory of your application. UberFx developers
 // can edit this via the APP_ROOT environment variable. If the environment
@@ -138,17 +179,87 @@ func (l *Loader) baseFiles() []string {
 }
 
 func (l *Loader) getResolver() FileResolver {
-	return NewRelativeResolver(l.Paths()...)
+	l.lock.RLock()
+	resolver := l.resolver
+	l.lock.RUnlock()
+
+	if resolver != nil {
+		l.lock.Lock()
+		l.searched = nil
+		l.lock.Unlock()
+
+		return resolver
+	}
+
+	paths := l.Paths()
+
+	l.lock.Lock()
+	l.searched = paths
+	l.lock.Unlock()
+
+	return NewRelativeResolver(paths...)
 }
 
-// YamlProvider returns function to create Yaml based configuration provider
+// YamlProvider returns function to create Yaml based configuration provider.
+// The function returns an error if a required file is missing.
 func (l *Loader) YamlProvider() ProviderFunc {
 	return func() (Provider, error) {
-		static := NewYAMLProviderFromFiles(false, l.getResolver(), l.getStaticFiles()...)
-		expanded := NewYAMLProviderWithExpand(false, l.getResolver(), os.LookupEnv, l.getFiles()...)
+		resolver := l.getResolver()
+		env := l.Environment()
+
+		lookUp, err := l.variableLookUp()
+		if err != nil {
+			return nil, err
+		}
+
+		variables := newVariableRecorder(lookUp)
+		render := l.templateRenderer(variables.LookUp)
+
+		var manifest Manifest
+		expandedFiles, err := l.openFiles(resolver, env, &manifest, render, l.getFiles())
+		if err != nil {
+			return nil, err
+		}
+
+		staticFiles, err := l.openFiles(resolver, env, &manifest, nil, l.getStaticFiles())
+		if err != nil {
+			return nil, err
+		}
+
+		parser := yamlParser{
+			tags:          l.getTagHandlers(),
+			lookUp:        variables.LookUp,
+			resolver:      resolver,
+			environment:   env,
+			caseSensitive: l.isCaseSensitive(),
+		}
+
+		static, err := newYAMLProviderCoreWithParser(parser, staticFiles...)
+		if err != nil {
+			return nil, err
+		}
+
+		expanded, err := newYAMLProviderWithExpand(parser, expander{lookUp: variables.LookUp, retype: true}, expandedFiles...)
+		if err != nil {
+			return nil, err
+		}
+
+		if !parser.caseSensitive {
+			err := caseConflict(Root,
+				yamlLayer{expanded.root.value, expanded.root.origin},
+				yamlLayer{static.root.value, static.root.origin})
+
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		// Static files will have higher priority than expanded.
-		return NewProviderGroup("yaml", expanded, static), nil
+		return manifestProvider{
+			Provider:  NewProviderGroup("yaml", NewCachedProvider(expanded), NewCachedProvider(static)),
+			manifest:  manifest,
+			variables: variables.report(),
+		}, nil
 	}
 }
 
@@ -161,13 +272,38 @@ func (l *Loader) Environment() string {
 	return _devEnv
 }
 
-// Paths returns paths to the yaml configurations
+// Paths returns paths to the yaml configurations, most important first.
+// If the ${prefix}_CONFIG_DIR environment variable is set, it is split on
+// the OS list separator and used instead of the loader dirs and dir finders.
 func (l *Loader) Paths() []string {
-	if path, ok := l.lookUp(l.EnvironmentPrefix() + _configDir); ok {
-		return []string{path}
+	if paths, ok := l.lookUp(l.EnvironmentPrefix() + _configDir); ok {
+		return filepath.SplitList(paths)
 	}
 
-	return l.dirs
+	l.lock.RLock()
+	dirs := append([]string(nil), l.dirs...)
+	finders := append([]DirFinder(nil), l.dirFinders...)
+	lookUp := l.lookUp
+	l.lock.RUnlock()
+
+	for _, find := range finders {
+		for _, dir := range find(lookUp) {
+			if !contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	return dirs
+}
+
+// SearchedDirs returns directories that were searched for configuration files
+// by the last Load or Reload call, most important first.
+func (l *Loader) SearchedDirs() []string {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return append([]string(nil), l.searched...)
 }
 
 // SetConfigFiles overrides the set of available config files for the service.
@@ -226,6 +362,42 @@ func (l *Loader) SetDirs(dirs ...string) {
 	l.dirs = dirs
 }
 
+// RegisterDirFinders registers functions to find more directories to load
+// config files from, e.g. FindUpFromWorkingDir("config"). Found directories
+// are searched after the loader dirs in the order of registration.
+func (l *Loader) RegisterDirFinders(finders ...DirFinder) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.dirFinders = append(l.dirFinders, finders...)
+}
+
+// SetFileResolver makes the loader resolve config files with the resolver
+// instead of looking for them in the loader dirs.
+func (l *Loader) SetFileResolver(resolver FileResolver) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.resolver = resolver
+}
+
+// SetCaseSensitive makes keys in config files that differ by case different
+// keys, e.g. Get("timeout") doesn't return a value for Timeout. By default
+// lookups ignore case and Load fails on keys that differ only by case.
+func (l *Loader) SetCaseSensitive(caseSensitive bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.caseSensitive = caseSensitive
+}
+
+func (l *Loader) isCaseSensitive() bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.caseSensitive
+}
+
 // SetEnvironmentPrefix sets environment prefix for the application.
 func (l *Loader) SetEnvironmentPrefix(envPrefix string) {
 	l.lock.Lock()
@@ -256,6 +428,10 @@ type ProviderFunc func() (Provider, error)
 // DynamicProviderFunc is used to create config providers on configuration initialization.
 type DynamicProviderFunc func(config Provider) (Provider, error)
 
+// ValidatorFunc is used to check a configuration before it is handed out by Load or Reload,
+// e.g. by populating a service config struct.
+type ValidatorFunc func(config Provider) error
+
 // RegisterProviders registers configuration providers for the global config.
 func (l *Loader) RegisterProviders(providerFuncs ...ProviderFunc) {
 	l.lock.Lock()
@@ -274,6 +450,14 @@ func (l *Loader) RegisterDynamicProviders(dynamicProviderFuncs ...DynamicProvide
 	l.dynamicProviderFuncs = append(l.dynamicProviderFuncs, dynamicProviderFuncs...)
 }
 
+// RegisterValidators registers functions to validate the configuration built by Load and Reload.
+func (l *Loader) RegisterValidators(validatorFuncs ...ValidatorFunc) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.validatorFuncs = append(l.validatorFuncs, validatorFuncs...)
+}
+
 // UnregisterProviders clears all the default providers.
 func (l *Loader) UnregisterProviders() {
 	l.lock.Lock()
@@ -283,35 +467,125 @@ func (l *Loader) UnregisterProviders() {
 	l.dynamicProviderFuncs = nil
 }
 
-// Load creates a Provider for use in a service.
+// Load creates a Provider for use in a service. It panics if any of the providers
+// can't be created or the configuration fails validation.
+// The returned provider is updated by subsequent Reload calls.
 func (l *Loader) Load() Provider {
+	p, info, err := l.build()
+	if err != nil {
+		panic(err)
+	}
+
+	r := NewReloadable(p)
+
+	l.lock.Lock()
+	l.reloadable = r
+	l.manifest = info.manifest
+	l.variableReport = info.variables
+	l.lock.Unlock()
+
+	return r
+}
+
+// Reload rebuilds all the registered static and dynamic providers and validates them.
+// If validation passes, the provider returned by the last Load call is atomically
+// updated and change callbacks are called for keys with changed values.
+// Otherwise the previous configuration stays in use and the error is returned.
+func (l *Loader) Reload() (err error) {
+	l.lock.RLock()
+	r := l.reloadable
+	l.lock.RUnlock()
+
+	if r == nil {
+		return errors.New("can't reload configuration before it is loaded")
+	}
+
+	// Providers panic on malformed files, which shouldn't bring a running service down.
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("failed to reload configuration: %v", e)
+		}
+	}()
+
+	p, info, err := l.build()
+	if err != nil {
+		return err
+	}
+
+	if err := r.Swap(p); err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	l.manifest = info.manifest
+	l.variableReport = info.variables
+	l.lock.Unlock()
+
+	return nil
+}
+
+// loadInfo describes what was used to build a configuration.
+type loadInfo struct {
+	manifest  Manifest
+	variables VariableReport
+}
+
+func (l *Loader) build() (Provider, loadInfo, error) {
+	l.lock.RLock()
+	staticProviderFuncs := append([]ProviderFunc(nil), l.staticProviderFuncs...)
+	dynamicProviderFuncs := append([]DynamicProviderFunc(nil), l.dynamicProviderFuncs...)
+	validatorFuncs := append([]ValidatorFunc(nil), l.validatorFuncs...)
+	l.lock.RUnlock()
+
 	var static []Provider
-	for _, providerFunc := range l.staticProviderFuncs {
+	var info loadInfo
+	for _, providerFunc := range staticProviderFuncs {
 		cp, err := providerFunc()
 		if err != nil {
-			panic(err)
+			return nil, loadInfo{}, err
 		}
 
-		static = append(static, cp)
+		if m, ok := cp.(manifestProvider); ok {
+			info.manifest = append(info.manifest, m.manifest...)
+			info.variables = info.variables.merge(m.variables)
+		}
+
+		if cp != nil {
+			static = append(static, cp)
+		}
 	}
 
-	baseCfg := NewProviderGroup("global", static...)
+	baseCfg := newRefProvider(NewProviderGroup("global", static...))
 
 	var dynamic []Provider
-	for _, providerFunc := range l.dynamicProviderFuncs {
+	for _, providerFunc := range dynamicProviderFuncs {
 		cp, err := providerFunc(baseCfg)
 		if err != nil {
-			panic(err)
+			return nil, loadInfo{}, err
 		}
 		if cp != nil {
 			dynamic = append(dynamic, cp)
 		}
 	}
 
-	return NewProviderGroup("global", append(static, dynamic...)...)
+	// References are resolved after all providers are merged, so values with
+	// higher priority are used in all values that reference them.
+	cfg := newRefProvider(NewProviderGroup("global", append(static, dynamic...)...))
+	if err := cfg.(refProvider).check(); err != nil {
+		return nil, loadInfo{}, err
+	}
+
+	for _, validatorFunc := range validatorFuncs {
+		if err := validatorFunc(cfg); err != nil {
+			return nil, loadInfo{}, errors.Wrap(err, "configuration validation failed")
+		}
+	}
+
+	return cfg, info, nil
 }
 
-// SetLookupFn sets the lookup function to get environment variables.
+// SetLookupFn sets the lookup function to get environment variables,
+// it is also used to interpolate values in config files.
 func (l *Loader) SetLookupFn(fn func(string) (string, bool)) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
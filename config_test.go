@@ -693,3 +693,47 @@ func TestLoader_OverrideStaticConfigFiles(t *testing.T) {
 
 	withBase(t, f, "value: base")
 }
+
+func TestLoader_MultipleDocuments(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetLookupFn(func(key string) (string, bool) {
+			if key == "APP_ENVIRONMENT" {
+				return "production", true
+			}
+
+			return "", false
+		})
+
+		p := l.Load()
+		assert.Equal(t, "db.internal", p.Get("db.host").String())
+		assert.Equal(t, 5432, p.Get("db.port").Value())
+		assert.False(t, p.Get(_whenKey).HasValue())
+	}
+
+	withBase(t, f, "db: {host: localhost, port: 5432}\n---\nwhen: {environment: production}\ndb: {host: db.internal}")
+}
+
+func TestLoader_Delete(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestLoader_Delete")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "base.yaml"), []byte("tracing:\n  sampler: 0.1\nport: 80"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "production.yaml"), []byte("tracing: !delete"), os.ModePerm))
+
+	l := NewLoader()
+	l.SetDirs(dir)
+	l.SetConfigFiles("base.yaml", "production.yaml")
+	p := l.Load()
+
+	assert.False(t, p.Get("tracing").HasValue())
+	assert.False(t, p.Get("tracing.sampler").HasValue())
+	assert.Equal(t, 80, p.Get("port").Value())
+	assert.Equal(t, map[interface{}]interface{}{"port": 80}, p.Get(Root).Value())
+}
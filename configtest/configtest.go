@@ -0,0 +1,234 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package configtest provides hermetic helpers for testing code that uses
+// configuration: loaders that never read the process environment or search
+// the disk for files, temporary overrides of config values and golden file
+// assertions for the effective configuration of every environment.
+package configtest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/config"
+)
+
+var _update = flag.Bool("configtest.update", false, "update configtest golden files")
+
+// Files maps config file names to their contents.
+type Files map[string]string
+
+// Env maps environment variable names to their values.
+type Env map[string]string
+
+// NewLoader returns a loader that reads config files only from files and
+// environment variables only from env, e.g. the environment name and values
+// for interpolation. Files that are not in the map don't exist. Templates
+// get localhost as the host name unless env sets APP_HOSTNAME.
+func NewLoader(files Files, env Env) *config.Loader {
+	l := config.NewLoader()
+	l.SetFileResolver(fileResolver(files))
+	l.SetLookupFn(func(key string) (string, bool) {
+		val, ok := env[key]
+		if !ok && key == l.EnvironmentPrefix()+"_HOSTNAME" {
+			return "localhost", true
+		}
+
+		return val, ok
+	})
+
+	return l
+}
+
+// ReadDir reads YAML files in the dir to use with NewLoader.
+// The test fails if the dir can't be read.
+func ReadDir(t testing.TB, dir string) Files {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		t.Fatalf("can't list config files in %q: %v", dir, err)
+	}
+
+	files := make(Files, len(paths))
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("can't read config file: %v", err)
+		}
+
+		files[filepath.Base(path)] = string(contents)
+	}
+
+	return files
+}
+
+// Override replaces values of dotted keys in p for the rest of the test and
+// restores the original values when the test completes. Change callbacks
+// are called in both cases. The provider has to be returned by Loader.Load,
+// and it shouldn't be shared with parallel tests.
+func Override(t testing.TB, p config.Provider, values map[string]interface{}) {
+	t.Helper()
+
+	r, ok := p.(*config.Reloadable)
+	if !ok {
+		t.Fatalf("can't override values in %T, a provider returned by Loader.Load is expected", p)
+	}
+
+	original := r.Current()
+	overrides := config.NewStaticProvider(nest(values))
+	if err := r.Swap(config.NewProviderGroup(r.Name(), original, overrides)); err != nil {
+		t.Fatalf("can't override config values: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := r.Swap(original); err != nil {
+			t.Errorf("can't restore config values: %v", err)
+		}
+	})
+}
+
+// nest turns dotted keys into nested maps, see config.ParsePath.
+func nest(values map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	for key, val := range values {
+		parts := config.ParsePath(key)
+		m := res
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				m[part] = child
+			}
+
+			m = child
+		}
+
+		m[parts[len(parts)-1]] = val
+	}
+
+	return res
+}
+
+// AssertGolden checks that the effective configuration of p, as it is written
+// by config.Dump in YAML, matches the golden file. Run tests with the
+// -configtest.update flag to write the golden file instead.
+func AssertGolden(t testing.TB, p config.Provider, golden string) bool {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	if err := config.Dump(p, buf, config.YAMLFormat); err != nil {
+		t.Errorf("can't dump config: %v", err)
+		return false
+	}
+
+	if *_update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Errorf("can't update golden file: %v", err)
+			return false
+		}
+
+		return true
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("can't read golden file, run tests with -configtest.update to create it: %v", err)
+		return false
+	}
+
+	if actual := buf.String(); actual != string(expected) {
+		t.Errorf("config doesn't match golden file %q, run tests with -configtest.update to update it.\n"+
+			"Expected:\n%s\nActual:\n%s", golden, expected, actual)
+		return false
+	}
+
+	return true
+}
+
+// AssertEnvironments loads files in every environment and checks the effective
+// configuration against the golden file ${environment}.yaml in goldenDir.
+// The environment name is added to env under the APP_ENVIRONMENT key.
+func AssertEnvironments(t testing.TB, files Files, env Env, goldenDir string, environments ...string) bool {
+	t.Helper()
+
+	ok := true
+	for _, environment := range environments {
+		envWithName := Env{"APP_ENVIRONMENT": environment}
+		for k, v := range env {
+			if k != "APP_ENVIRONMENT" {
+				envWithName[k] = v
+			}
+		}
+
+		p, err := load(NewLoader(files, envWithName))
+		if err != nil {
+			t.Errorf("can't load config for the %q environment: %v", environment, err)
+			ok = false
+			continue
+		}
+
+		ok = AssertGolden(t, p, filepath.Join(goldenDir, environment+".yaml")) && ok
+	}
+
+	return ok
+}
+
+// load turns a Load panic into an error.
+func load(l *config.Loader) (p config.Provider, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if err, _ = e.(error); err == nil {
+				panic(e)
+			}
+		}
+	}()
+
+	return l.Load(), nil
+}
+
+// fileResolver resolves files from memory.
+type fileResolver Files
+
+func (f fileResolver) Resolve(file string) io.ReadCloser {
+	contents, ok := f[file]
+	if !ok {
+		return nil
+	}
+
+	return namedReader{Reader: strings.NewReader(contents), name: file}
+}
+
+// namedReader reports a file name, so it shows up in origins and the manifest.
+type namedReader struct {
+	io.Reader
+
+	name string
+}
+
+func (n namedReader) Name() string { return n.name }
+
+func (n namedReader) Close() error { return nil }
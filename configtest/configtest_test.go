@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package configtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
+)
+
+func TestNewLoader(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader(
+		Files{"base.yaml": "user: ${USER:nobody}\nhome: ${HOME:none}", "spy.yaml": "me: Austin Powers"},
+		Env{"APP_ENVIRONMENT": "spy", "USER": "austin"},
+	)
+
+	p := l.Load()
+	assert.Equal(t, "austin", p.Get("user").AsString())
+	assert.Equal(t, "none", p.Get("home").AsString(), "real environment shouldn't be used")
+	assert.Equal(t, "Austin Powers", p.Get("me").AsString())
+	assert.Equal(t, config.Manifest{
+		{Name: "base.yaml", Path: "base.yaml", SHA256: "e4011794e6780ea7326cc098c029045e446359d6a1fbda0a9a88b2f1b676ce65"},
+		{Name: "spy.yaml", Path: "spy.yaml", SHA256: "d9a9ae0d72e8e405fbb7990f26c4aaf36d4c726d75a1ce7aa2c8fe231e3ab272"},
+	}, l.Manifest())
+	assert.Empty(t, l.SearchedDirs())
+
+	e, err := config.Explain(p, "me")
+	require.NoError(t, err)
+	assert.Equal(t, "spy.yaml:1:5 (yaml)", e.Origin.String())
+}
+
+func TestNewLoader_FileTag(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader(
+		Files{"base.yaml": "cert: !file certs/cert.pem\nuser: !env USER", "certs/cert.pem": "CERTIFICATE"},
+		Env{"USER": "austin"},
+	)
+
+	p := l.Load()
+	assert.Equal(t, "CERTIFICATE", p.Get("cert").AsString(), "files should be read from memory")
+	assert.Equal(t, "austin", p.Get("user").AsString())
+}
+
+func TestNewLoader_Hostname(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader(Files{"base.yaml": "host: {{ .Hostname }}"}, nil)
+	l.EnableTemplates(nil)
+	assert.Equal(t, "localhost", l.Load().Get("host").AsString(), "real hostname shouldn't be used")
+
+	l = NewLoader(Files{"base.yaml": "host: {{ .Hostname }}"}, Env{"APP_HOSTNAME": "batcave"})
+	l.EnableTemplates(nil)
+	assert.Equal(t, "batcave", l.Load().Get("host").AsString())
+}
+
+func TestNewLoader_RequiredFiles(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader(Files{}, nil)
+	l.RequireFiles(config.AllEnvironments, "base.yaml")
+
+	_, err := load(l)
+	assert.EqualError(t, err, `required config file "base.yaml" is not found`)
+}
+
+func TestReadDir(t *testing.T) {
+	t.Parallel()
+
+	files := ReadDir(t, filepath.Join("testdata", "config"))
+	assert.Equal(t, "db:\n  host: db.gotham.prod\n", files["production.yaml"])
+	assert.Len(t, files, 3)
+}
+
+func TestOverride(t *testing.T) {
+	t.Parallel()
+
+	p := NewLoader(Files{"base.yaml": "db:\n  host: localhost\n  port: 5432"}, nil).Load()
+
+	var changed []interface{}
+	require.NoError(t, p.RegisterChangeCallback("db.host", func(key string, provider string, data interface{}) {
+		changed = append(changed, data)
+	}))
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, p, map[string]interface{}{"db.host": "db.test", "db.name": "test"})
+
+		assert.Equal(t, "db.test", p.Get("db.host").AsString())
+		assert.Equal(t, "test", p.Get("db.name").AsString())
+		assert.Equal(t, 5432, p.Get("db.port").AsInt())
+	})
+
+	assert.Equal(t, "localhost", p.Get("db.host").AsString())
+	assert.False(t, p.Get("db.name").HasValue())
+	assert.Equal(t, []interface{}{"db.test", "localhost"}, changed)
+}
+
+func TestOverride_DynamicProvider(t *testing.T) {
+	t.Parallel()
+
+	mock := config.NewMockDynamicProvider(map[string]interface{}{"db.host": "vault"})
+	l := NewLoader(Files{"base.yaml": "hosts:\n  example.com: {port: 80}"}, nil)
+	l.RegisterDynamicProviders(func(config.Provider) (config.Provider, error) { return mock, nil })
+	p := l.Load()
+
+	var changed []interface{}
+	require.NoError(t, p.RegisterChangeCallback("db.host", func(key string, provider string, data interface{}) {
+		changed = append(changed, data)
+	}))
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, p, map[string]interface{}{`hosts.example\.com.port`: 8080})
+		assert.Equal(t, 8080, p.Get(`hosts.example\.com.port`).AsInt())
+	})
+
+	assert.Equal(t, 80, p.Get(`hosts.example\.com.port`).AsInt())
+
+	mock.Set("db.host", "db.test")
+	assert.Equal(t, []interface{}{"db.test"}, changed)
+}
+
+func TestAssertEnvironments(t *testing.T) {
+	t.Parallel()
+
+	files := ReadDir(t, filepath.Join("testdata", "config"))
+	assert.True(t, AssertEnvironments(t, files, nil, filepath.Join("testdata", "golden"), "development", "production"))
+}
+
+func TestAssertGolden_Mismatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestAssertGolden_Mismatch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	golden := filepath.Join(dir, "golden.yaml")
+	require.NoError(t, ioutil.WriteFile(golden, []byte("a: b\n"), os.ModePerm))
+
+	p := config.NewStaticProvider(map[string]string{"a": "b"})
+	assert.True(t, AssertGolden(t, p, golden))
+
+	mock := &failureRecorder{TB: t}
+	assert.False(t, AssertGolden(mock, config.NewStaticProvider(map[string]string{"a": "c"}), golden))
+	assert.False(t, AssertGolden(mock, p, filepath.Join(dir, "missing.yaml")))
+	require.Len(t, mock.errors, 2)
+	assert.Contains(t, mock.errors[0], "config doesn't match golden file")
+	assert.Contains(t, mock.errors[1], "can't read golden file")
+}
+
+// failureRecorder records errors instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+
+	errors []string
+}
+
+func (r *failureRecorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -25,7 +25,10 @@ import (
 	"encoding"
 	"fmt"
 	"math"
+	"net"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 
 	"github.com/go-validator/validator"
@@ -229,6 +232,34 @@ func errorWithKey(err error, key string) error {
 	return errors.Wrap(err, fmt.Sprintf("for key %q", key))
 }
 
+// errorWithKey adds the key and, for values from files, the position of
+// the value to the error, e.g. config/base.yaml:12:7: for key "port": ...
+func (d *decoder) errorWithKey(err error, key string) error {
+	return d.withPosition(errorWithKey(err, key), key)
+}
+
+func (d *decoder) withPosition(err error, key string) error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := d.getGlobalProvider().(Explainer)
+	if !ok {
+		return err
+	}
+
+	origins := e.Origins(key)
+	if len(origins) == 0 {
+		return err
+	}
+
+	if pos := origins[len(origins)-1].location(); pos != "" {
+		return errors.Wrap(err, pos)
+	}
+
+	return err
+}
+
 type decoder struct {
 	*Value
 	m map[interface{}]struct{}
@@ -254,7 +285,7 @@ func (d *decoder) scalar(childKey string, value reflect.Value, def string) error
 		val = def
 	}
 
-	return convert(childKey, &value, val)
+	return d.withPosition(convert(childKey, &value, val), childKey)
 }
 
 // Set value for a sequence type
@@ -352,13 +383,13 @@ func (d *decoder) mapping(childKey string, value reflect.Value, def string) erro
 			subKey := fmt.Sprintf("%v", key)
 			if subKey == "" {
 				// We can confuse an empty map key with a root element.
-				return errorWithKey(errors.New("empty map key is ambiguous"), childKey)
+				return d.errorWithKey(errors.New("empty map key is ambiguous"), childKey)
 			}
 
 			itemValue := reflect.New(valueType.Elem()).Elem()
 
 			// Try to unmarshal value and save it in the map.
-			if err := d.unmarshal(childKey+subKey, itemValue, def); err != nil {
+			if err := d.unmarshal(childKey+escapeKey(subKey), itemValue, def); err != nil {
 				return err
 			}
 
@@ -391,7 +422,7 @@ func (d *decoder) iface(key string, value reflect.Value, def string) error {
 		return nil
 	}
 
-	return errorWithKey(fmt.Errorf("%q doesn't implement %q", src.Type(), value.Type()), key)
+	return d.errorWithKey(fmt.Errorf("%q doesn't implement %q", src.Type(), value.Type()), key)
 }
 
 // Sets value to an object type.
@@ -435,7 +466,7 @@ func (d *decoder) valueStruct(key string, target interface{}) error {
 		}
 	}
 
-	return errorWithKey(validator.Validate(target), key)
+	return d.errorWithKey(validator.Validate(target), key)
 }
 
 // If there is no value with name - leave it nil, otherwise allocate memory and set the value.
@@ -475,12 +506,12 @@ func (d *decoder) textUnmarshaller(key string, value reflect.Value, str string)
 
 	// Value has to have a pointer receiver to be able to modify itself with TextUnmarshaller
 	if !value.CanAddr() {
-		return errorWithKey(errors.New("can't use TextUnmarshaller because value is not addressable"), key)
+		return d.errorWithKey(errors.New("can't use TextUnmarshaller because value is not addressable"), key)
 	}
 
 	switch t := value.Addr().Interface().(type) {
 	case encoding.TextUnmarshaler:
-		return errorWithKey(t.UnmarshalText([]byte(str)), key)
+		return d.errorWithKey(t.UnmarshalText([]byte(str)), key)
 	}
 
 	return nil
@@ -510,10 +541,33 @@ func (d *decoder) checkCycles(value reflect.Value) error {
 	return nil
 }
 
+var (
+	_typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	_typeOfURL             = reflect.TypeOf(url.URL{})
+	_typeOfIPNet           = reflect.TypeOf(net.IPNet{})
+	_typeOfRegexp          = reflect.TypeOf(regexp.Regexp{})
+)
+
+// isTextType returns true for structs and slices that are populated from strings,
+// e.g. time.Time or net.IP, instead of maps and sequences.
+func isTextType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct && t.Kind() != reflect.Slice {
+		return false
+	}
+
+	return t == _typeOfURL || t == _typeOfIPNet || t == _typeOfRegexp || reflect.PtrTo(t).Implements(_typeOfTextUnmarshaler)
+}
+
 // Dispatch un-marshalling functions based on the value type.
 func (d *decoder) unmarshal(name string, value reflect.Value, def string) error {
 	if err := d.checkCycles(value); err != nil {
-		return errorWithKey(err, name)
+		return d.errorWithKey(err, name)
+	}
+
+	if isTextType(value.Type()) {
+		if _, ok := d.getGlobalProvider().Get(name).Value().(string); ok {
+			return d.scalar(name, value, def)
+		}
 	}
 
 	switch value.Kind() {
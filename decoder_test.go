@@ -23,6 +23,7 @@ package config
 import (
 	"fmt"
 	"math"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/gofuzz"
@@ -548,3 +549,34 @@ func TestAddKeyToErrorReturnsNilForNilErrors(t *testing.T) {
 
 	assert.NoError(t, errorWithKey(nil, "key"))
 }
+
+func TestDecoder_ErrorPositions(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		p := l.Load()
+
+		var cfg struct {
+			Port int `yaml:"port"`
+		}
+
+		err := p.Get("db").Populate(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "base.yaml")+`:3:9: for key "db.port": strconv.ParseInt`)
+
+		var s struct {
+			Name string `validate:"nonzero"`
+		}
+
+		err = p.Get("db").Populate(&s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "base.yaml")+`:2:3: for key "db"`)
+
+		err = NewYAMLProviderFromBytes([]byte("port: ten")).Get(Root).Populate(&cfg)
+		assert.EqualError(t, err, `for key "port": strconv.ParseInt: parsing "ten": invalid syntax`)
+	}
+
+	withBase(t, f, "db:\n  host: localhost\n  port: ten")
+}
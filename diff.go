@@ -0,0 +1,184 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// A ChangeKind describes how a value changed between two configurations.
+type ChangeKind int
+
+const (
+	// Added values are defined only in the new configuration.
+	Added ChangeKind = iota
+	// Removed values are defined only in the old configuration.
+	Removed
+	// Modified values are defined in both configurations with different values.
+	Modified
+)
+
+// String returns a lower case name of the kind, e.g. "added".
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// A Change is a difference in a single scalar value or an empty collection.
+type Change struct {
+	Key  string
+	Kind ChangeKind
+
+	// Old and New values, nil if the value is not defined. Values that may hold
+	// secrets are replaced with "<redacted>" by the default Dumper rules.
+	Old, New interface{}
+
+	// OldType and NewType are types of the values before redaction.
+	OldType, NewType ValueType
+
+	// OldOrigin and NewOrigin are definitions of the values in use,
+	// nil if the value is not defined or the provider didn't report an origin.
+	OldOrigin, NewOrigin *Origin
+}
+
+// TypeChanged returns true if the value is defined in both configurations
+// with different types, e.g. a port changed from 80 to "80".
+func (c Change) TypeChanged() bool {
+	return c.Kind == Modified && c.OldType != c.NewType
+}
+
+// String returns a one line description of the change,
+// e.g. "~ db.port: 5432 -> 5433".
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Key, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Key, c.Old)
+	}
+
+	return fmt.Sprintf("~ %s: %v -> %v", c.Key, c.Old, c.New)
+}
+
+// Diff returns changes of scalar values and empty collections from the effective
+// configuration of a to the effective configuration of b, e.g. to compare
+// a staging configuration with a production one. Changes are ordered by key,
+// list indexes are compared as numbers. Values that may hold secrets are
+// redacted with the default Dumper rules, but still reported when they change.
+func Diff(a, b Provider) []Change {
+	d := Dumper{RedactKeys: DefaultRedactKeys, RedactFiles: []string{_secretsFile}}
+
+	var res []Change
+	for _, key := range sortedPaths(mergeKeys(keysOf(a, Root), keysOf(b, Root))) {
+		old, hasOld := leafOf(a, key)
+		updated, hasUpdated := leafOf(b, key)
+
+		c := Change{Key: key, Kind: Modified}
+		switch {
+		case !hasOld && !hasUpdated:
+			continue
+		case !hasOld:
+			c.Kind = Added
+		case !hasUpdated:
+			c.Kind = Removed
+		case reflect.DeepEqual(old, updated):
+			continue
+		}
+
+		if hasOld {
+			c.OldType = GetType(old)
+			c.Old, c.OldOrigin = d.leaf(a, key, old)
+		}
+
+		if hasUpdated {
+			c.NewType = GetType(updated)
+			c.New, c.NewOrigin = d.leaf(b, key, updated)
+		}
+
+		res = append(res, c)
+	}
+
+	return res
+}
+
+// leafOf returns the value for the key if it is a scalar or an empty collection.
+// A key that holds a scalar in one configuration can hold a map in the other,
+// the map is reported by its own leaves then.
+func leafOf(p Provider, key string) (interface{}, bool) {
+	v := p.Get(key)
+	if !v.HasValue() {
+		return nil, false
+	}
+
+	switch val := v.Value().(type) {
+	case map[interface{}]interface{}:
+		return val, len(val) == 0
+	case []interface{}:
+		return val, len(val) == 0
+	}
+
+	return v.Value(), true
+}
+
+// sortedPaths sorts dotted keys segment by segment comparing numeric segments
+// as numbers, so "list.2" goes before "list.10".
+func sortedPaths(keys []string) []string {
+	paths := make([]Path, len(keys))
+	for i, key := range keys {
+		paths[i] = ParsePath(key)
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] == b[k] {
+				continue
+			}
+
+			x, errX := strconv.Atoi(a[k])
+			y, errY := strconv.Atoi(b[k])
+			if errX == nil && errY == nil {
+				return x < y
+			}
+
+			return a[k] < b[k]
+		}
+
+		return len(a) < len(b)
+	})
+
+	for i, path := range paths {
+		keys[i] = path.String()
+	}
+
+	return keys
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	staging := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: staging.db
+  port: 5432
+  password: hunter2
+hosts: [a, b, c, d, e, f, g, h, i, j, k]
+debug: true
+tags: []
+`))
+
+	production := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: prod.db
+  port: "5432"
+  password: swordfish
+hosts: [a, b, c, d, e, f, g, h, i, j, x]
+timeout: 1s
+tags: []
+`))
+
+	changes := Diff(staging, production)
+
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{
+		"~ db.host: staging.db -> prod.db",
+		"~ db.password: <redacted> -> <redacted>",
+		"~ db.port: 5432 -> 5432",
+		"- debug: true",
+		"~ hosts.10: k -> x",
+		"+ timeout: 1s",
+	}, lines)
+
+	port := changes[2]
+	assert.True(t, port.TypeChanged())
+	assert.Equal(t, Integer, port.OldType)
+	assert.Equal(t, String, port.NewType)
+	require.NotNil(t, port.OldOrigin)
+	assert.Equal(t, 4, port.OldOrigin.Line)
+	require.NotNil(t, port.NewOrigin)
+	assert.Equal(t, 4, port.NewOrigin.Line)
+
+	assert.False(t, changes[0].TypeChanged())
+	assert.Equal(t, Removed, changes[3].Kind)
+	assert.Nil(t, changes[3].New)
+	assert.Nil(t, changes[3].NewOrigin)
+	assert.Equal(t, Added, changes[5].Kind)
+	assert.Nil(t, changes[5].Old)
+	assert.Nil(t, changes[5].OldOrigin)
+}
+
+func TestDiff_Equal(t *testing.T) {
+	t.Parallel()
+
+	p := NewStaticProvider(map[string]interface{}{"a": map[string]interface{}{"b": 1}, "c": []int{1, 2}})
+	assert.Empty(t, Diff(p, p))
+	assert.Empty(t, Diff(NewStaticProvider(nil), NewStaticProvider(nil)))
+}
+
+func TestDiff_Collections(t *testing.T) {
+	t.Parallel()
+
+	a := NewYAMLProviderFromBytes([]byte("a: 1\nb: {c: 2}"))
+	b := NewYAMLProviderFromBytes([]byte("a: {x: 1}\nb: 2"))
+
+	var lines []string
+	for _, c := range Diff(a, b) {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{"- a: 1", "+ a.x: 1", "+ b: 2", "- b.c: 2"}, lines)
+}
+
+func TestDiff_Secrets(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestDiff_Secrets")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	load := func(secret string) Provider {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _baseFile), []byte("api: !secret API_KEY"), os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _secretsFile), []byte("db: "+secret), os.ModePerm))
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetLookupFn(func(key string) (string, bool) { return secret, key == "API_KEY" })
+		return l.Load()
+	}
+
+	changes := Diff(load("old"), load("new"))
+	require.Len(t, changes, 2)
+	for _, c := range changes {
+		assert.Equal(t, _redacted, c.Old, c.Key)
+		assert.Equal(t, _redacted, c.New, c.Key)
+	}
+}
+
+func TestChangeKind_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "added", Added.String())
+	assert.Equal(t, "removed", Removed.String())
+	assert.Equal(t, "modified", Modified.String())
+	assert.Equal(t, "ChangeKind(42)", ChangeKind(42).String())
+}
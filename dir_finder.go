@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DirFinder returns directories to search for configuration files, most
+// important first. It uses lookUp to read environment variables.
+type DirFinder func(lookUp func(string) (string, bool)) []string
+
+// FindUpFromWorkingDir returns a DirFinder that walks up from the current working
+// directory and returns the first directory named marker it finds, e.g. it finds
+// "/app/config" for marker "config" when a test runs in "/app/pkg/server".
+func FindUpFromWorkingDir(marker string) DirFinder {
+	return func(func(string) (string, bool)) []string {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+
+		return findUp(cwd, marker)
+	}
+}
+
+// FindUpFromExecutable returns a DirFinder that walks up from the directory of
+// the running binary and returns the first directory named marker it finds.
+func FindUpFromExecutable(marker string) DirFinder {
+	return func(func(string) (string, bool)) []string {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil
+		}
+
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+
+		return findUp(filepath.Dir(exe), marker)
+	}
+}
+
+// XDGConfigDirs returns a DirFinder for the app directories in the XDG base
+// directories: $XDG_CONFIG_HOME/app (~/.config/app by default), followed by
+// app directories in $XDG_CONFIG_DIRS (/etc/xdg by default).
+func XDGConfigDirs(app string) DirFinder {
+	return func(lookUp func(string) (string, bool)) []string {
+		var dirs []string
+		if home, ok := lookUp("XDG_CONFIG_HOME"); ok && home != "" {
+			dirs = append(dirs, filepath.Join(home, app))
+		} else if home, ok := lookUp("HOME"); ok && home != "" {
+			dirs = append(dirs, filepath.Join(home, ".config", app))
+		}
+
+		system, ok := lookUp("XDG_CONFIG_DIRS")
+		if !ok || system == "" {
+			system = "/etc/xdg"
+		}
+
+		for _, dir := range filepath.SplitList(system) {
+			if dir != "" {
+				dirs = append(dirs, filepath.Join(dir, app))
+			}
+		}
+
+		return dirs
+	}
+}
+
+// findUp returns the first directory named marker in start or its ancestors.
+func findUp(start, marker string) []string {
+	for dir := filepath.Clean(start); ; {
+		candidate := filepath.Join(dir, marker)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return []string{candidate}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+
+		dir = parent
+	}
+}
+
+func contains(dirs []string, dir string) bool {
+	for _, d := range dirs {
+		if d == dir {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUp(t *testing.T) {
+	t.Parallel()
+
+	root, err := ioutil.TempDir("", "TestFindUp")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	nested := filepath.Join(root, "pkg", "server")
+	require.NoError(t, os.MkdirAll(nested, os.ModePerm))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "config"), os.ModePerm))
+
+	// Files with the marker name are skipped.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "pkg", "config"), nil, os.ModePerm))
+
+	assert.Equal(t, []string{filepath.Join(root, "config")}, findUp(nested, "config"))
+	assert.Equal(t, []string{filepath.Join(root, "config")}, findUp(root, "config"))
+	assert.Nil(t, findUp(nested, "missing-marker-dir"))
+}
+
+func TestFindUpFromWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	dirs := FindUpFromWorkingDir("testdata")(nil)
+	assert.Equal(t, []string{filepath.Join(cwd, "testdata")}, dirs)
+}
+
+func TestXDGConfigDirs(t *testing.T) {
+	t.Parallel()
+
+	lookUp := func(env map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			val, ok := env[key]
+			return val, ok
+		}
+	}
+
+	find := XDGConfigDirs("gotham")
+	assert.Equal(t, []string{"/home/bruce/.config/gotham", "/etc/xdg/gotham"},
+		find(lookUp(map[string]string{"HOME": "/home/bruce"})))
+
+	assert.Equal(t, []string{"/cfg/gotham", "/a/gotham", "/b/gotham"}, find(lookUp(map[string]string{
+		"HOME":            "/home/bruce",
+		"XDG_CONFIG_HOME": "/cfg",
+		"XDG_CONFIG_DIRS": strings.Join([]string{"/a", "", "/b"}, string(filepath.ListSeparator)),
+	})))
+}
+
+func TestLoader_DirFinders(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs("missing")
+		l.RegisterDirFinders(
+			func(func(string) (string, bool)) []string { return []string{"missing", dir} },
+			func(func(string) (string, bool)) []string { return nil },
+		)
+
+		p := l.Load()
+		assert.Equal(t, "base", p.Get("value").AsString())
+		assert.Equal(t, []string{"missing", dir}, l.SearchedDirs())
+	}
+
+	withBase(t, f, "value: base")
+}
+
+func TestLoader_ConfigDirList(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.RegisterDirFinders(func(func(string) (string, bool)) []string {
+			assert.Fail(t, "finders should not be called when APP_CONFIG_DIR is set")
+			return nil
+		})
+
+		dirs := strings.Join([]string{"missing", dir}, string(filepath.ListSeparator))
+		l.SetLookupFn(func(key string) (string, bool) {
+			if key == "APP_CONFIG_DIR" {
+				return dirs, true
+			}
+
+			return "", false
+		})
+
+		assert.Empty(t, l.SearchedDirs())
+		p := l.Load()
+		assert.Equal(t, "base", p.Get("value").AsString())
+		assert.Equal(t, []string{"missing", dir}, l.SearchedDirs())
+	}
+
+	withBase(t, f, "value: base")
+}
@@ -165,6 +165,21 @@
 //   fmt.Println(composer)
 //   // Output: {Beethoven 1756}
 //
+// Map keys with dots, e.g. example.com or 10.0.0.1, can be ambiguous with
+// the longest match. Escape dots in them with a backslash or use GetPath()
+// with a segment per key. Keys with escaped dots are matched segment by segment:
+//
+//   port := config.GetPath(provider, "hosts", "example.com", "port")
+//   port = provider.Get(`hosts.example\.com.port`)
+//
+// Populate and Keys() escape map keys the same way, so keys with dots round-trip.
+//
+// Keys are case insensitive by default: Get("http.timeout") finds HTTP.Timeout.
+// Since such keys are ambiguous, a YAML provider fails to load if two keys in the
+// same map, possibly from different files, differ only by case. Use
+// Loader.SetCaseSensitive(true) or NewCaseSensitiveYAMLProviderFromReader()
+// to match keys exactly and allow such keys.
+//
 // • Populate a struct (Populate(&myStruct))
 //
 // The As* method has two variants: TryAs* and As*. The former is a
@@ -176,6 +191,62 @@
 // As* will
 // panic.
 //
+// Richer types have accessors that return an error instead: Duration(),
+// Time(layouts...), ByteSize() (e.g. 512MiB or 1.5GB), Percent()
+// (e.g. 75% is 0.75), URL(), IP(), CIDR(), Regexp(), Strings()
+// and StringMap(). They convert values with the same rules as Populate, so
+// a config.ByteSize or a *url.URL field gets the same value as the accessor:
+//
+//   timeout, err := provider.Get("http.timeout").Duration()
+//   cache, err := provider.Get("cache.size").ByteSize()
+//
+// With Go 1.18 or later, generic helpers populate any type with Populate,
+// so default tags and validation still apply:
+//
+//   timeout, err := config.Get[time.Duration](provider, "http.timeout")
+//   port, err := config.GetOr(provider, "http.port", 8080)
+//   name := config.MustGet[string](provider, "service.name")
+//
+//   // limits.Load() returns the latest value after every change of the key.
+//   limits, err := config.Watch[Limits](provider, "limits", nil)
+//
+// Keys() lists sorted dotted keys of all the scalars under a value, e.g.
+// db.hosts.0 and db.port, and Walk() visits them in the same order:
+//
+//   err := provider.Get(config.Root).Walk(func(key string, v config.Value) error {
+//     fmt.Println(key, v)
+//     return nil
+//   })
+//
+// Providers that hold values in other shapes implement Enumerable to list
+// their keys. All the providers in this package do.
+//
+// Query() finds values with wildcards, indexes and filters and returns them
+// with their dotted keys:
+//
+//   servers.*.port              ports of all the servers
+//   servers[0].port             port of the first server
+//   hosts["example.com"].port   a key with dots
+//   clients[?enabled].name      names of clients with a non-empty enabled value
+//   clients[?tier==gold].name   names of clients with tier: gold, != works too
+//
+//   matches, err := config.Query(provider, "servers.*.port")
+//   for _, m := range matches {
+//     fmt.Println(m.Path, m.Value)
+//   }
+//
+// LastUpdated() tells when a value was loaded or changed: YAML values report
+// the latest modification time of files that define them, dynamic providers
+// report when a value changed and groups report the latest of their layers.
+// Version() increases every time a provider loads or changes values, so
+// comparing versions is a cheap way to tell that a value may have changed.
+// Values of providers that don't track updates have version zero:
+//
+//   if v := provider.Get("limits"); v.Version() > seen {
+//     seen = v.Version()
+//     // apply the new limits
+//   }
+//
 // Populate
 //
 // Populate is akin to json.Unmarshal() in that it takes a pointer to a
@@ -204,6 +275,11 @@
 // Note that any fields you wish to deserialize into must be exported, just like
 // json.Unmarshal and friends.
 //
+// Errors for values loaded from files start with the position of the value,
+// e.g. config/production.yaml:42:7: for key "api.timeout": .... Syntax errors,
+// unknown tags and maps that can't be merged into other values are reported
+// the same way.
+//
 // Environment variables
 //
 // The YAML provider supports accepting values from the environment in which the process
@@ -218,6 +294,103 @@
 // variable and checks for a value to use. If the YAML provider doesn't find a value,
 // it uses the provided 3001 default.
 //
+// Expressions follow the shell syntax:
+//
+//   ${VAR}        value of VAR, loading fails if VAR is not set
+//   ${VAR:def}    value of VAR or def if VAR is not set
+//   ${VAR:-def}   value of VAR or def if VAR is not set or empty
+//   ${VAR:?msg}   value of VAR, loading fails with msg if it is not set or empty
+//   ${VAR:+alt}   alt if VAR is set and not empty, empty string otherwise
+//   $${VAR}       literal ${VAR}
+//
+// Defaults can be nested, e.g. ${PRIMARY_HOST:${HOST:localhost}}. Errors
+// include the key and the file position of the value that can't be interpolated.
+// NewExpandedYAMLProvider() returns them, while the older *WithExpand
+// constructors panic.
+//
+// Values without expressions keep their types. An unquoted value that is a single
+// expression gets the type of the expanded text, so the port above is an integer.
+// Quote it, e.g. "${HTTP_PORT:3001}", to keep it a string.
+//
+// Loader looks up variables in a chain: values set with
+// Loader.SetVariables()
+// come first, then the lookup function set with
+// Loader.SetLookupFn()
+// (os.LookupEnv by default) and then files with NAME=value lines set with
+// Loader.SetEnvFiles(). After a load,
+// Loader.Variables() reports which
+// variables were referenced and which of them were missing.
+//
+// For loops and conditionals,
+// Loader.EnableTemplates() renders config files
+// with text/template before they are parsed. Templates can use
+// {{ .Environment }}, {{ .Hostname }}, {{ env "NAME" }} and any functions
+// passed to EnableTemplates():
+//
+//   shards:
+//   {{- range $i, $_ := seq (env "SHARDS") }}
+//     - shard-{{ $i }}
+//   {{- end }}
+//   {{ if eq .Environment "development" }}debug: true{{ end }}
+//
+// Template errors include the file and the line. Static files, e.g.
+// secrets.yaml, are not rendered. .Hostname is the value of the
+// APP_HOSTNAME variable if it is set, so tests can pin it.
+//
+// Values loaded by Loader can reference other keys with ${ref:key}:
+//
+//   db:
+//     host: localhost
+//     primary:
+//       url: postgres://${ref:db.host}/users
+//     replica:
+//       url: postgres://${ref:db.host}/users?replica
+//
+// References are resolved after all the files and providers are merged, so
+// overriding db.host in production.yaml updates both URLs. A value that is
+// a single reference gets the referenced value as is, e.g. a number or a map.
+// Load fails on references to undefined keys and on reference cycles, which
+// are reported with the full chain, e.g. reference cycle: a -> b -> a.
+// Use $${ref:key} for a literal ${ref:key}.
+//
+// YAML tags resolve values at load time:
+//
+//   port: !env PORT            # 8080 is an integer, like an unquoted value
+//   cert: !file certs/tls.pem  # relative to the directory of the YAML file
+//   banner: !base64 aGVsbG8=
+//   timeout: !duration 5m      # time.Duration
+//   db:
+//     password: !secret DB_PASSWORD
+//
+// !env and !secret look up variables the same way ${} does, and
+// Dump always redacts values tagged with !secret. !file opens files with the
+// loader's FileResolver. These three tags are only available in files read by
+// the Loader, other YAML providers never read host files or variables and only
+// understand !base64 and !duration. Register handlers for other tags
+// with Loader.RegisterTagHandlers(). Unknown tags are rejected with the file
+// and the position, e.g. config/base.yaml:3:7: unknown tag "!foo".
+// Dump and Export write durations as strings, e.g. 5m0s, so they can be
+// read back.
+//
+// A file can hold several YAML documents separated with ---. They are merged
+// in order, like separate files. In files read by the Loader, a document with
+// a when selector only applies to the listed environments, so a single file can
+// hold the base configuration and its per-environment overlays. Other YAML
+// providers have no environment and treat when as a regular key:
+//
+//   log: {level: info}
+//   ---
+//   when: {environment: [development, test]}
+//   log: {level: debug}
+//
+// A null value, e.g. key: ~, doesn't override a value from a lower priority file
+// or provider, IsNull() tells it apart from a missing key. Tag a key with
+// !delete to remove it and everything under it from lower priority files and
+// providers. A higher priority file can define the key again:
+//
+//   # production.yaml
+//   tracing: !delete
+//
 //
 // Command-line arguments
 //
@@ -352,6 +525,24 @@
 // Loader.RegisterProviders()) that will
 // override values of the default configs.
 //
+// The configtest package has hermetic helpers for such tests.
+// configtest.NewLoader() returns a loader that reads files and environment
+// variables only from the given maps,
+// configtest.Override() replaces values for
+// the rest of a test and
+// configtest.AssertEnvironments() checks the effective
+// configuration of every environment against checked-in golden files:
+//
+//   func TestConfig(t *testing.T) {
+//     files := configtest.ReadDir(t, "config")
+//     configtest.AssertEnvironments(t, files, nil, "testdata", "development", "production")
+//
+//     p := configtest.NewLoader(files, configtest.Env{"APP_ENVIRONMENT": "production"}).Load()
+//     configtest.Override(t, p, map[string]interface{}{"db.host": "localhost"})
+//   }
+//
+// Run tests with the -configtest.update flag to update the golden files.
+//
 //
 // Utilities
 //
@@ -399,6 +590,45 @@
 // from files.
 //
 //
+// • Explain(p Provider, key string) tells where a value came from: the provider,
+// file, line and column of the definition in use and all the definitions it
+// overrode in lower priority files and providers:
+//
+//   e, err := config.Explain(cfg, "db.host")
+//   fmt.Println(e.Origin, "overrides", e.Overridden)
+//   // Output: config/production.yaml:3:9 (yaml) overrides [config/base.yaml:3:9 (yaml)]
+//
+//
+// • Dump(p Provider, w io.Writer, format DumpFormat) writes the effective
+// configuration as YAML or JSON, e.g. to log it at startup. Values of keys that
+// look like credentials and values loaded from secrets.yaml, or built from
+// ${ref:key} references to them, are replaced with <redacted>. Use
+// a Dumper to change the redaction rules or to annotate every value with
+// its origin:
+//
+//   d := config.Dumper{Annotate: true, SensitiveProviders: []string{"vault"}}
+//   err := d.Dump(cfg, os.Stdout)
+//
+//
+// • Diff(a, b Provider) compares the effective configurations of two providers,
+// e.g. staging and production, and returns added, removed and modified values
+// ordered by key. A Change holds old and new values, their types and origins.
+// Secrets are redacted the same way Dump does it:
+//
+//   for _, c := range config.Diff(staging, production) {
+//     fmt.Println(c, "at", c.NewOrigin)
+//   }
+//   // Output: ~ db.port: 5432 -> 5433 at config/production.yaml:4:9 (yaml)
+//
+//
+// • Export(p Provider, format DumpFormat) serializes the effective configuration
+// as YAML or JSON with string map keys, e.g. to pass it to a child process.
+// Unlike Dump, it doesn't redact secrets. A Value implements yaml.Marshaler
+// and json.Marshaler, so a part of the configuration can be serialized as is:
+//
+//   b, err := json.Marshal(cfg.Get("sidecar"))
+//
+//
 // Loading Configuration
 //
 // The load process is controlled by Loader. If a service doesn't
@@ -413,9 +643,27 @@
 // the current directory and then in the
 // ./config directory. You can override
 // directories to look for these files with
-// Loader.SetDirs().
+// Loader.SetDirs() or with the
+// APP_CONFIG_DIR environment variable, which holds a list of directories
+// separated by the OS list separator (: on Unix). More directories can be
+// discovered with
+// Loader.RegisterDirFinders():
+// FindUpFromWorkingDir("config")
+// and
+// FindUpFromExecutable("config") walk up the file tree looking for a
+// config directory, e.g. when tests run in package subdirectories, and
+// XDGConfigDirs("app") returns the XDG config directories of the app.
+// Loader.SearchedDirs() returns the directories searched by the last load.
 // To override file names, use
 // Loader.SetFiles().
+// All files are optional by default. Use
+// Loader.RequireFiles() to make
+// Load() fail when a file is missing, either in every environment
+// (config.AllEnvironments) or in a specific one, and
+// Loader.OptionalFiles()
+// to relax a requirement for an environment.
+// Loader.Manifest() lists files
+// loaded by the last load with their paths and SHA-256 checksums.
 //
 // • The command-line provider looks for --roles argument to specify service
 // roles. Use
@@ -451,6 +699,34 @@
 // override it with your custom function:
 // DefaultLoader.SetLookupFn().
 //
+// Reloading configuration
+//
+// Providers returned by Loader.Load() can be refreshed with Loader.Reload().
+// It rebuilds all registered static and dynamic providers and runs validators
+// registered with Loader.RegisterValidators(), e.g. populating your service
+// config struct. If validation passes, the loaded provider atomically switches to
+// the new configuration and change callbacks are called for keys that changed.
+// Otherwise the old configuration stays in use and Reload() returns an error.
+//
+//   loader.RegisterValidators(func(p config.Provider) error {
+//     var cfg serviceConfig
+//     return p.Get(config.Root).Populate(&cfg)
+//   })
+//
+// You can also reload configuration when the process receives a signal, like
+// kill -HUP does for nginx. Reloads are rate limited with MinInterval and
+// their results are reported to the OnReload hook:
+//
+//   stop := loader.ReloadOnSignal(config.SignalReloadConfig{
+//     MinInterval: time.Second,
+//     OnReload: func(err error) {
+//       if err != nil {
+//         logger.Error("failed to reload config", zap.Error(err))
+//       }
+//     },
+//   })
+//   defer stop()
+//
 // Benchmarks
 //
 // Current performance benchmark data:
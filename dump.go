@@ -0,0 +1,284 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// DumpFormat is a serialization format used by Dump.
+type DumpFormat int
+
+const (
+	// YAMLFormat renders configuration as YAML.
+	YAMLFormat DumpFormat = iota
+	// JSONFormat renders configuration as indented JSON.
+	JSONFormat
+)
+
+const _redacted = "<redacted>"
+
+// DefaultRedactKeys matches keys that usually hold credentials.
+var DefaultRedactKeys = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private_?key|api_?key)`),
+}
+
+// A Dumper renders the effective configuration of a provider, e.g. to log it
+// at startup. Values that may hold secrets, including values tagged with
+// !secret, are replaced with "<redacted>".
+type Dumper struct {
+	Format DumpFormat
+
+	// RedactKeys are patterns of dotted keys to redact. DefaultRedactKeys
+	// are used if RedactKeys is nil.
+	RedactKeys []*regexp.Regexp
+
+	// RedactFiles are base names of files with values to redact, the loader's
+	// secrets file is redacted if RedactFiles is nil.
+	RedactFiles []string
+
+	// SensitiveProviders are names of providers with values to redact.
+	SensitiveProviders []string
+
+	// Annotate adds origins of values to the output: YAML values get
+	// a comment and JSON values are replaced with {"value": ..., "origin": ...} objects.
+	Annotate bool
+}
+
+// Dump writes the effective configuration of p to w in the requested format
+// redacting secrets with the default rules.
+func Dump(p Provider, w io.Writer, format DumpFormat) error {
+	return Dumper{Format: format}.Dump(p, w)
+}
+
+// Dump writes the effective configuration of p to w.
+func (d Dumper) Dump(p Provider, w io.Writer) error {
+	if d.RedactKeys == nil {
+		d.RedactKeys = DefaultRedactKeys
+	}
+
+	if d.RedactFiles == nil {
+		d.RedactFiles = []string{_secretsFile}
+	}
+
+	root := p.Get(Root).Value()
+	switch d.Format {
+	case YAMLFormat:
+		enc := yaml3.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(d.yamlNode(p, Root, root)); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d.jsonValue(p, Root, root))
+	}
+
+	return fmt.Errorf("unknown dump format: %d", d.Format)
+}
+
+func (d Dumper) yamlNode(p Provider, key string, value interface{}) *yaml3.Node {
+	node := &yaml3.Node{}
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		node.Kind = yaml3.MappingNode
+		for _, k := range sortedKeys(v) {
+			keyNode := &yaml3.Node{}
+			keyNode.Encode(k)
+			node.Content = append(node.Content, keyNode, d.yamlNode(p, joinKey(key, k), v[k]))
+		}
+	case []interface{}:
+		node.Kind = yaml3.SequenceNode
+		for i, item := range v {
+			node.Content = append(node.Content, d.yamlNode(p, joinKey(key, strconv.Itoa(i)), item))
+		}
+	default:
+		val, origin := d.leaf(p, key, value)
+		node.Encode(val)
+		if d.Annotate && origin != nil {
+			node.LineComment = origin.String()
+		}
+	}
+
+	return node
+}
+
+func (d Dumper) jsonValue(p Provider, key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[fmt.Sprint(k)] = d.jsonValue(p, joinKey(key, k), item)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = d.jsonValue(p, joinKey(key, strconv.Itoa(i)), item)
+		}
+
+		return res
+	}
+
+	val, origin := d.leaf(p, key, value)
+	if !d.Annotate || origin == nil {
+		return val
+	}
+
+	return map[string]interface{}{"value": val, "origin": origin.String()}
+}
+
+// leaf returns a value to render for the key and the origin of the value.
+func (d Dumper) leaf(p Provider, key string, value interface{}) (interface{}, *Origin) {
+	var origin *Origin
+	if origins := originsOf(p, key); len(origins) > 0 {
+		origin = &origins[len(origins)-1]
+	}
+
+	if d.redacted(key, origin) || d.referencesRedacted(p, key, map[string]bool{key: true}) {
+		return _redacted, origin
+	}
+
+	if dur, ok := value.(time.Duration); ok {
+		return dur.String(), origin
+	}
+
+	return value, origin
+}
+
+// referencesRedacted returns true if the value for the key is built from ${ref:key}
+// references to redacted values, e.g. a DSN with a password from secrets.yaml.
+// Keys without origins can come from a parent that references a map, e.g.
+// "creds.pass" for "creds: ${ref:db}" is checked as "db.pass".
+func (d Dumper) referencesRedacted(p Provider, key string, seen map[string]bool) bool {
+	path := ParsePath(key)
+	for i := len(path); i > 0; i-- {
+		origins := originsOf(p, path[:i].String())
+		if len(origins) == 0 {
+			continue
+		}
+
+		s, ok := origins[len(origins)-1].Value.(string)
+		if !ok || !strings.Contains(s, "${"+_refPrefix) {
+			return false
+		}
+
+		refs, err := parseReferences(s)
+		if err != nil {
+			return false
+		}
+
+		for _, f := range refs {
+			if f.variable == nil {
+				continue
+			}
+
+			ref := f.variable.name
+			for _, k := range path[i:] {
+				ref = joinKey(ref, k)
+			}
+
+			if !seen[ref] {
+				seen[ref] = true
+				if d.redactedKey(p, ref, seen) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// redactedKey returns true if the value for the key or any value it references is redacted.
+func (d Dumper) redactedKey(p Provider, key string, seen map[string]bool) bool {
+	var origin *Origin
+	if origins := originsOf(p, key); len(origins) > 0 {
+		origin = &origins[len(origins)-1]
+	}
+
+	return d.redacted(key, origin) || d.referencesRedacted(p, key, seen)
+}
+
+func (d Dumper) redacted(key string, origin *Origin) bool {
+	for _, re := range d.RedactKeys {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+
+	if origin == nil {
+		return false
+	}
+
+	if origin.Tag == _secretTag {
+		return true
+	}
+
+	for _, name := range d.SensitiveProviders {
+		if origin.Provider == name {
+			return true
+		}
+	}
+
+	for _, file := range d.RedactFiles {
+		if origin.File != "" && filepath.Base(origin.File) == file {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinKey appends a map key or an index to a dotted key escaping separators in it.
+func joinKey(prefix string, key interface{}) string {
+	return addSeparator(prefix) + escapeKey(fmt.Sprint(key))
+}
+
+// sortedKeys returns map keys sorted by their string representation.
+func sortedKeys(m map[interface{}]interface{}) []interface{} {
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	return keys
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dumpYaml = []byte(`
+service:
+  name: gotham
+  ports: [80, 443]
+  enabled: yes
+db:
+  user: bruce
+  password: alfred
+`)
+
+func TestDump_YAML(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(NewYAMLProviderFromBytes(dumpYaml), buf, YAMLFormat))
+	assert.Equal(t, `db:
+  password: <redacted>
+  user: bruce
+service:
+  enabled: true
+  name: gotham
+  ports:
+    - 80
+    - 443
+`, buf.String())
+}
+
+func TestDump_JSON(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(NewYAMLProviderFromBytes(dumpYaml), buf, JSONFormat))
+	assert.JSONEq(t, `{
+  "db": {"password": "<redacted>", "user": "bruce"},
+  "service": {"enabled": true, "name": "gotham", "ports": [80, 443]}
+}`, buf.String())
+}
+
+func TestDump_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Dump(NewStaticProvider(nil), &bytes.Buffer{}, DumpFormat(42))
+	assert.EqualError(t, err, "unknown dump format: 42")
+}
+
+func TestDumper_Redaction(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestDumper_Redaction")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _baseFile), []byte("db:\n  host: localhost"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _secretsFile), []byte("db:\n  pass: hunter2"), os.ModePerm))
+
+	l := NewLoader(func() (Provider, error) {
+		return NewMockDynamicProvider(map[string]interface{}{"db.host": "vault"}), nil
+	})
+
+	l.SetDirs(dir)
+	p := l.Load()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(p, buf, YAMLFormat))
+	assert.Equal(t, "db:\n  host: localhost\n  pass: <redacted>\n", buf.String())
+
+	buf.Reset()
+	d := Dumper{
+		RedactKeys:         []*regexp.Regexp{regexp.MustCompile(`^db\.host$`)},
+		RedactFiles:        []string{},
+		SensitiveProviders: []string{"MockDynamicProvider"},
+	}
+
+	require.NoError(t, d.Dump(p, buf))
+	assert.Equal(t, "db:\n  host: <redacted>\n  pass: hunter2\n", buf.String())
+}
+
+func TestDumper_RedactReferences(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestDumper_RedactReferences")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	load := func(pass string) Provider {
+		base := "dsn: postgres://u:${ref:db.pass}@h/db\ncreds: ${ref:db}\nhost: ${ref:db.host}\ndb:\n  host: h"
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _baseFile), []byte(base), os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, _secretsFile), []byte("db:\n  pass: "+pass), os.ModePerm))
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		return l.Load()
+	}
+
+	p := load("hunter2")
+	assert.Equal(t, "postgres://u:hunter2@h/db", p.Get("dsn").Value())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(p, buf, YAMLFormat))
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "dsn: <redacted>")
+	assert.Contains(t, buf.String(), "creds:\n  host: h\n  pass: <redacted>")
+	assert.Contains(t, buf.String(), "host: h\n")
+
+	var lines []string
+	for _, c := range Diff(p, load("swordfish")) {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{
+		"~ creds.pass: <redacted> -> <redacted>",
+		"~ db.pass: <redacted> -> <redacted>",
+		"~ dsn: <redacted> -> <redacted>",
+	}, lines)
+}
+
+func TestDumper_Annotate(t *testing.T) {
+	t.Parallel()
+
+	p := NewProviderGroup("global",
+		NewYAMLProviderFromBytes([]byte("hero: Batman\nlist: [a]")),
+		NewStaticProvider(map[string]string{"villain": "Joker"}),
+	)
+
+	d := Dumper{Annotate: true}
+	buf := &bytes.Buffer{}
+	require.NoError(t, d.Dump(p, buf))
+	assert.Equal(t, `hero: Batman # yaml:1:7
+list:
+  - a # yaml:2:8
+villain: Joker # static
+`, buf.String())
+
+	d.Format = JSONFormat
+	buf.Reset()
+	require.NoError(t, d.Dump(p, buf))
+	assert.JSONEq(t, `{
+  "hero": {"value": "Batman", "origin": "yaml:1:7"},
+  "list": [{"value": "a", "origin": "yaml:2:8"}],
+  "villain": {"value": "Joker", "origin": "static"}
+}`, buf.String())
+}
+
+func TestDump_ScalarRoot(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(NewStaticProvider(nil), buf, JSONFormat))
+	assert.Equal(t, "null\n", buf.String())
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// MarshalYAML serializes the value and all values under it with string map keys.
+// An undefined value is serialized as null.
+func (cv Value) MarshalYAML() (interface{}, error) {
+	return stringKeys(cv.Value()), nil
+}
+
+// MarshalJSON serializes the value and all values under it, map keys are
+// converted to strings. An undefined value is serialized as null.
+func (cv Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringKeys(cv.Value()))
+}
+
+// Export serializes the effective configuration of p as YAML or JSON, e.g. to
+// pass it to a child process. Use p.Get(key) with yaml.Marshal or json.Marshal
+// to export a part of the configuration. Unlike Dump, Export doesn't redact
+// secrets and doesn't annotate values.
+func Export(p Provider, format DumpFormat) ([]byte, error) {
+	root := stringKeys(p.Get(Root).Value())
+
+	var buf bytes.Buffer
+	switch format {
+	case YAMLFormat:
+		enc := yaml3.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	case JSONFormat:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown export format: %d", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// stringKeys returns a copy of the value tree with map keys converted to strings,
+// encoding/json can't serialize maps with interface{} keys. Durations are
+// converted to strings too, e.g. "5m0s".
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[fmt.Sprint(k)] = stringKeys(item)
+		}
+
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[k] = stringKeys(item)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = stringKeys(item)
+		}
+
+		return res
+	case time.Duration:
+		// Durations are written the way !duration and Populate read them.
+		return v.String()
+	}
+
+	return value
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+const _exportYAML = `
+db:
+  hosts: [a, b]
+  port: 5432
+ports:
+  80: http
+  443: https
+`
+
+func TestValue_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte(_exportYAML))
+
+	b, err := json.Marshal(p.Get("db"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hosts": ["a", "b"], "port": 5432}`, string(b))
+
+	b, err = json.Marshal(p.Get("ports"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"80": "http", "443": "https"}`, string(b))
+
+	b, err = json.Marshal(map[string]Value{"port": p.Get("db.port"), "missing": p.Get("missing")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"port": 5432, "missing": null}`, string(b))
+}
+
+func TestValue_MarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte(_exportYAML))
+
+	b, err := yaml3.Marshal(p.Get("ports"))
+	require.NoError(t, err)
+	assert.Equal(t, "\"80\": http\n\"443\": https\n", string(b))
+
+	b, err = yaml3.Marshal(p.Get("db.hosts"))
+	require.NoError(t, err)
+	assert.Equal(t, "- a\n- b\n", string(b))
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	base := NewYAMLProviderFromBytes([]byte(_exportYAML))
+	p := NewProviderGroup("group", base, NewStaticProvider(map[string]interface{}{
+		"db": map[string]interface{}{"password": "hunter2"},
+	}))
+
+	b, err := Export(p, YAMLFormat)
+	require.NoError(t, err)
+	assert.Equal(t, `db:
+  hosts:
+    - a
+    - b
+  password: hunter2
+  port: 5432
+ports:
+  "80": http
+  "443": https
+`, string(b))
+
+	b, err = Export(p, JSONFormat)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "db": {"hosts": ["a", "b"], "password": "hunter2", "port": 5432},
+  "ports": {"80": "http", "443": "https"}
+}`, string(b))
+
+	reloaded := NewYAMLProviderFromBytes(b)
+	assert.Equal(t, "hunter2", reloaded.Get("db.password").String())
+	assert.Equal(t, "https", reloaded.Get("ports.443").String())
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := Export(NewStaticProvider(nil), DumpFormat(42))
+	assert.EqualError(t, err, "unknown export format: 42")
+}
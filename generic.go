@@ -0,0 +1,106 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Get populates a value of type T from the key with Populate, so default
+// tags and validation apply. A missing key results in a zero value with
+// defaults, use GetOr to fall back to another value.
+//
+//	timeout, err := config.Get[time.Duration](provider, "http.timeout")
+func Get[T any](p Provider, key string) (T, error) {
+	var res T
+	err := p.Get(key).Populate(&res)
+	return res, err
+}
+
+// GetOr returns the value for the key converted to T, or def if the key is missing.
+func GetOr[T any](p Provider, key string, def T) (T, error) {
+	if !p.Get(key).HasValue() {
+		return def, nil
+	}
+
+	return Get[T](p, key)
+}
+
+// MustGet returns the value for the key converted to T, or panics if it
+// can't be converted.
+func MustGet[T any](p Provider, key string) T {
+	res, err := Get[T](p, key)
+	if err != nil {
+		panic(fmt.Sprintf("can't get the value for the key %q: %v", key, err))
+	}
+
+	return res
+}
+
+// Watched holds the latest value of a key converted to T.
+type Watched[T any] struct {
+	lock  sync.RWMutex
+	value T
+}
+
+// Load returns the latest value that was successfully converted.
+func (w *Watched[T]) Load() T {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.value
+}
+
+// Watch gets the value for the key converted to T and registers a change
+// callback in the provider to keep it up to date. onChange, if not nil, is
+// called with every new value or a conversion error, in which case the
+// previous value is kept. Like other change callbacks, it can be unregistered
+// with p.UnregisterChangeCallback(key).
+func Watch[T any](p Provider, key string, onChange func(T, error)) (*Watched[T], error) {
+	value, err := Get[T](p, key)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watched[T]{value: value}
+	err = p.RegisterChangeCallback(key, func(string, string, interface{}) {
+		value, err := Get[T](p, key)
+		if err == nil {
+			w.lock.Lock()
+			w.value = value
+			w.lock.Unlock()
+		}
+
+		if onChange != nil {
+			onChange(value, err)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
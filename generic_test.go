@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte("timeout: 5s\nport: 8080\nname: gotham\nhttp: {port: 80}"))
+
+	timeout, err := Get[time.Duration](p, "timeout")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	port, err := Get[int](p, "port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	_, err = Get[int](p, "name")
+	assert.Error(t, err)
+
+	type server struct {
+		Host string `yaml:"host" default:"localhost"`
+		Port int    `yaml:"port"`
+	}
+
+	s, err := Get[server](p, "http")
+	require.NoError(t, err)
+	assert.Equal(t, server{Host: "localhost", Port: 80}, s)
+
+	missing, err := GetOr(p, "missing", 42)
+	require.NoError(t, err)
+	assert.Equal(t, 42, missing)
+
+	port, err = GetOr(p, "port", 42)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	assert.Equal(t, "gotham", MustGet[string](p, "name"))
+	assert.Panics(t, func() { MustGet[int](p, "name") })
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	r := NewReloadable(NewStaticProvider(map[string]string{"timeout": "1s"}))
+
+	var errs []error
+	w, err := Watch[time.Duration](r, "timeout", func(_ time.Duration, err error) {
+		errs = append(errs, err)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, w.Load())
+
+	require.NoError(t, r.Swap(NewStaticProvider(map[string]string{"timeout": "2s"})))
+	assert.Equal(t, 2*time.Second, w.Load())
+
+	require.NoError(t, r.Swap(NewStaticProvider(map[string]string{"timeout": "soon"})))
+	assert.Equal(t, 2*time.Second, w.Load(), "invalid values should be ignored")
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+
+	_, err = Watch[int](r, "timeout", nil)
+	assert.Error(t, err)
+
+	_, err = Watch[string](r, "timeout", nil)
+	assert.EqualError(t, err, "callback already registered for the key: timeout")
+}
+
+func TestWatch_DynamicProvider(t *testing.T) {
+	t.Parallel()
+
+	set := func(m *MockDynamicProvider, key string, value interface{}) {
+		done := make(chan struct{})
+		go func() {
+			m.Set(key, value)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "change callback is blocked")
+		}
+	}
+
+	m := NewMockDynamicProvider(map[string]interface{}{"timeout": "1s"})
+	w, err := Watch[time.Duration](m, "timeout", nil)
+	require.NoError(t, err)
+
+	set(m, "timeout", "2s")
+	assert.Equal(t, 2*time.Second, w.Load())
+
+	dynamic := NewMockDynamicProvider(map[string]interface{}{"timeout": "1s"})
+	l := NewLoader()
+	l.SetStaticConfigFiles()
+	l.SetConfigFiles()
+	l.RegisterDynamicProviders(func(Provider) (Provider, error) { return dynamic, nil })
+
+	w, err = Watch[time.Duration](l.Load(), "timeout", nil)
+	require.NoError(t, err)
+
+	set(dynamic, "timeout", "3s")
+	assert.Equal(t, 3*time.Second, w.Load())
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// An interpolation is a parsed string with ${...} expressions.
+// The grammar follows the shell parameter expansion:
+//
+//	$$             a literal $, e.g. $${VAR} is a literal ${VAR}
+//	$VAR, ${VAR}   value of VAR, it is an error if VAR is not set
+//	${VAR:def}     value of VAR or def if VAR is not set
+//	${VAR:-def}    value of VAR or def if VAR is not set or empty
+//	${VAR:?msg}    value of VAR or an error with msg if VAR is not set or empty
+//	${VAR:+alt}    alt if VAR is set and not empty, empty string otherwise
+//
+// Words after operators can have nested expressions, e.g. ${A:${B:x}}.
+// Nested expressions are evaluated only if the word is used.
+//
+// References to other keys ${ref:key} and their escapes $${ref:key} are kept
+// as is, they are resolved after all the configuration layers are merged.
+type interpolation []fragment
+
+// fragment is either a literal or a variable expression.
+type fragment struct {
+	literal  string
+	variable *variable
+}
+
+type variable struct {
+	name string
+	op   string
+	word interpolation
+}
+
+const (
+	_opDefault      = ":"
+	_opDefaultEmpty = ":-"
+	_opError        = ":?"
+	_opAlternative  = ":+"
+)
+
+// parseInterpolation parses s into an interpolation.
+func parseInterpolation(s string) (interpolation, error) {
+	p := interpolationParser{input: s}
+	return p.parse(false)
+}
+
+type interpolationParser struct {
+	input string
+	pos   int
+}
+
+// parse reads fragments until the end of input or an unmatched } in a nested word.
+func (p *interpolationParser) parse(nested bool) (interpolation, error) {
+	var res interpolation
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			res = append(res, fragment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if nested && c == '}' {
+			break
+		}
+
+		if c != '$' || p.pos+1 == len(p.input) {
+			literal.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		switch next := p.input[p.pos+1]; {
+		case next == '$':
+			if strings.HasPrefix(p.input[p.pos+2:], "{"+_refPrefix) {
+				literal.WriteString("$$")
+			} else {
+				literal.WriteByte('$')
+			}
+
+			p.pos += 2
+		case next == '{' && strings.HasPrefix(p.input[p.pos+2:], _refPrefix):
+			end := strings.IndexByte(p.input[p.pos:], '}')
+			if end == -1 {
+				return nil, errors.Errorf("in %q at position %d: unterminated ${", p.input, p.pos)
+			}
+
+			literal.WriteString(p.input[p.pos : p.pos+end+1])
+			p.pos += end + 1
+		case next == '{':
+			start := p.pos
+			p.pos += 2
+			v, err := p.braced()
+			if err != nil {
+				return nil, errors.Wrapf(err, "in %q at position %d", p.input, start)
+			}
+
+			flush()
+			res = append(res, fragment{variable: v})
+		case isNameStart(next):
+			p.pos++
+			start := p.pos
+			for p.pos < len(p.input) && isNameChar(p.input[p.pos]) {
+				p.pos++
+			}
+
+			flush()
+			res = append(res, fragment{variable: &variable{name: p.input[start:p.pos]}})
+		default:
+			literal.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+	return res, nil
+}
+
+// braced parses an expression after ${ up to and including the closing }.
+func (p *interpolationParser) braced() (*variable, error) {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != ':' && p.input[p.pos] != '}' {
+		p.pos++
+	}
+
+	if p.pos == len(p.input) {
+		return nil, errors.New("unterminated ${")
+	}
+
+	v := &variable{name: p.input[start:p.pos]}
+	if v.name == "" {
+		return nil, errors.New("empty variable name")
+	}
+
+	if p.input[p.pos] == ':' {
+		v.op = _opDefault
+		p.pos++
+		if p.pos < len(p.input) && strings.IndexByte("-?+", p.input[p.pos]) >= 0 {
+			v.op += p.input[p.pos : p.pos+1]
+			p.pos++
+		}
+
+		word, err := p.parse(true)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.pos == len(p.input) {
+			return nil, errors.New("unterminated ${")
+		}
+
+		v.word = word
+	}
+
+	// Skip the closing brace.
+	p.pos++
+	return v, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
+
+// expand evaluates the interpolation with variables from lookUp.
+func (in interpolation) expand(lookUp lookUpFunc) (string, error) {
+	var res strings.Builder
+	for _, f := range in {
+		if f.variable == nil {
+			res.WriteString(f.literal)
+			continue
+		}
+
+		val, err := f.variable.expand(lookUp)
+		if err != nil {
+			return "", err
+		}
+
+		res.WriteString(val)
+	}
+
+	return res.String(), nil
+}
+
+func (v *variable) expand(lookUp lookUpFunc) (string, error) {
+	val, ok := lookUp(v.name)
+	switch v.op {
+	case "":
+		if !ok {
+			return "", fmt.Errorf("variable %q is not set", v.name)
+		}
+
+		return val, nil
+	case _opDefault:
+		if ok {
+			return val, nil
+		}
+
+		// A quoted empty string is kept for backward compatibility.
+		if len(v.word) == 1 && v.word[0].literal == _emptyDefault {
+			return "", nil
+		}
+	case _opDefaultEmpty:
+		if ok && val != "" {
+			return val, nil
+		}
+	case _opError:
+		if ok && val != "" {
+			return val, nil
+		}
+
+		msg, err := v.word.expand(lookUp)
+		if err != nil {
+			return "", err
+		}
+
+		if msg == "" {
+			return "", fmt.Errorf("variable %q is not set or empty", v.name)
+		}
+
+		return "", fmt.Errorf("%s: %s", v.name, msg)
+	case _opAlternative:
+		if !ok || val == "" {
+			return "", nil
+		}
+	}
+
+	return v.word.expand(lookUp)
+}
+
+// interpolate expands ${...} expressions in s.
+func interpolate(s string, lookUp lookUpFunc) (string, error) {
+	in, err := parseInterpolation(s)
+	if err != nil {
+		return "", err
+	}
+
+	return in.expand(lookUp)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"HOST": "gotham", "EMPTY": "", "PORT": "80"}
+	lookUp := func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	}
+
+	tests := map[string]string{
+		"plain":                  "plain",
+		"${HOST}":                "gotham",
+		"$HOST:$PORT":            "gotham:80",
+		"http://${HOST}:${PORT}": "http://gotham:80",
+		"$${HOST}":               "${HOST}",
+		"$$HOST":                 "$HOST",
+		"5$ and $ and $1":        "5$ and $ and $1",
+		"end$":                   "end$",
+		"${MISSING:a:b}":         "a:b",
+		"${MISSING:}":            "",
+		`${MISSING:""}`:          "",
+		"${EMPTY:default}":       "",
+		"${EMPTY:-default}":      "default",
+		"${MISSING:-default}":    "default",
+		"${HOST:-default}":       "gotham",
+		"${HOST:+alt}":           "alt",
+		"${EMPTY:+alt}":          "",
+		"${MISSING:+alt}":        "",
+		"${HOST:?not set}":       "gotham",
+		"${MISSING:${HOST:x}}":   "gotham",
+		"${MISSING:${NONE:x}y}":  "xy",
+		"${HOST:${NONE}}":        "gotham",
+		"${MISSING:$${HOST}}":    "${HOST}",
+		"${MISSING:-{}}":         "{}",
+		"${MISSING:-a}}":         "a}",
+	}
+
+	for in, expected := range tests {
+		actual, err := interpolate(in, lookUp)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, expected, actual, in)
+		}
+	}
+
+	errs := map[string]string{
+		"${MISSING}":              `variable "MISSING" is not set`,
+		"$MISSING":                `variable "MISSING" is not set`,
+		"${MISSING:${NONE}}":      `variable "NONE" is not set`,
+		"${EMPTY:?}":              `variable "EMPTY" is not set or empty`,
+		"${MISSING:?need a host}": `MISSING: need a host`,
+		"${}":                     `in "${}" at position 0: empty variable name`,
+		"a ${HOST":                `in "a ${HOST" at position 2: unterminated ${`,
+		"${HOST:-${PORT}":         `in "${HOST:-${PORT}" at position 0: unterminated ${`,
+	}
+
+	for in, expected := range errs {
+		_, err := interpolate(in, lookUp)
+		assert.EqualError(t, err, expected, in)
+	}
+}
+
+func TestLoader_InterpolationErrors(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetLookupFn(func(string) (string, bool) { return "", false })
+
+		assert.Panics(t, func() { l.Load() })
+
+		l.SetLookupFn(func(key string) (string, bool) { return "x", key == "DB_HOST" })
+		l.Load()
+
+		l.SetLookupFn(func(string) (string, bool) { return "", false })
+		err := l.Reload()
+		require.Error(t, err)
+		assert.Equal(t,
+			filepath.Join(dir, _baseFile)+
+				`:5:7: can't interpolate the value for the key "db.hosts.1": DB_HOST: database host is required`,
+			err.Error())
+	}
+
+	withBase(t, f, strings.Join([]string{
+		"db:",
+		"  port: 5432",
+		"  hosts:",
+		"    - localhost",
+		"    - ${DB_HOST:?database host is required}",
+	}, "\n"))
+}
+
+func TestNewExpandedYAMLProvider(t *testing.T) {
+	t.Parallel()
+
+	lookUp := func(key string) (string, bool) { return "db.prod", key == "DB_HOST" }
+	read := func(doc string) io.ReadCloser {
+		return namedReader{Reader: strings.NewReader(doc), name: "base.yaml"}
+	}
+
+	p, err := NewExpandedYAMLProvider(lookUp, read("db:\n  host: ${DB_HOST}"))
+	require.NoError(t, err)
+	assert.Equal(t, "db.prod", p.Get("db.host").Value())
+
+	_, err = NewExpandedYAMLProvider(lookUp, read("db:\n  port: ${DB_PORT}"))
+	assert.EqualError(t, err, `base.yaml:2:9: can't interpolate the value for the key "db.port": variable "DB_PORT" is not set`)
+
+	assert.Panics(t, func() { NewYAMLProviderFromReaderWithExpand(lookUp, read("port: ${DB_PORT}")) })
+}
+
+func TestYAMLProviderWithExpand_KeepsTypes(t *testing.T) {
+	t.Parallel()
+
+	lookUp := func(key string) (string, bool) {
+		val, ok := map[string]string{"PORT": "8080", "DEBUG": "yes", "RATIO": "0.5", "EMPTY": ""}[key]
+		return val, ok
+	}
+
+	p := NewYAMLProviderFromReaderWithExpand(lookUp, ioutil.NopCloser(strings.NewReader(`
+db:
+  port: 5432
+  enabled: true
+  name: ${NAME:users}
+server:
+  port: ${PORT:80}
+  default: ${MISSING:443}
+  debug: ${DEBUG}
+  ratio: ${RATIO}
+  empty: ${EMPTY}
+  quoted: "${PORT}"
+  tagged: !!str ${PORT}
+  text: port ${PORT}
+  list:
+    - ${PORT}`)))
+
+	assert.Equal(t, map[interface{}]interface{}{"port": 5432, "enabled": true, "name": "users"},
+		p.Get("db").Value())
+	assert.Equal(t, map[interface{}]interface{}{
+		"port":    8080,
+		"default": 443,
+		"debug":   true,
+		"ratio":   0.5,
+		"empty":   "",
+		"quoted":  "8080",
+		"tagged":  "8080",
+		"text":    "port 8080",
+		"list":    []interface{}{8080},
+	}, p.Get("server").Value())
+	assert.Equal(t, Integer, p.Get("server.port").Type)
+}
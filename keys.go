@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"sort"
+	"strconv"
+)
+
+// An Enumerable is a Provider that can list the keys it holds.
+type Enumerable interface {
+	// Keys returns sorted dotted keys of all scalar values under the key,
+	// e.g. "db.hosts.0" and "db.port" for the key "db".
+	Keys(key string) []string
+}
+
+// keysOf returns keys under the key in p, falling back to a walk over
+// the value tree for providers that don't implement Enumerable.
+func keysOf(p Provider, key string) []string {
+	if p == nil {
+		return nil
+	}
+
+	if e, ok := p.(Enumerable); ok {
+		return e.Keys(key)
+	}
+
+	v := p.Get(key)
+	if !v.HasValue() {
+		return nil
+	}
+
+	return treeKeys(key, v.Value())
+}
+
+// treeKeys returns sorted keys of all scalars and empty collections in the value tree.
+func treeKeys(key string, value interface{}) []string {
+	var res []string
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case deletion:
+			return
+		case map[interface{}]interface{}:
+			for k, item := range v {
+				walk(joinKey(key, k), item)
+			}
+
+			if len(v) > 0 {
+				return
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(joinKey(key, strconv.Itoa(i)), item)
+			}
+
+			if len(v) > 0 {
+				return
+			}
+		}
+
+		if key != Root {
+			res = append(res, key)
+		}
+	}
+
+	walk(key, value)
+	sort.Strings(res)
+	return res
+}
+
+// mergeKeys returns sorted unique keys from all the lists.
+func mergeKeys(lists ...[]string) []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for _, keys := range lists {
+		for _, key := range keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				res = append(res, key)
+			}
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// Keys returns sorted dotted keys of all scalar values under the value,
+// starting with the key of the value, e.g. "db.hosts.0" and "db.port".
+func (cv Value) Keys() []string {
+	if cv.provider == nil {
+		return treeKeys(cv.key, cv.Value())
+	}
+
+	return keysOf(cv.provider, cv.key)
+}
+
+// Walk calls f for all scalar values under the value in the order of Keys.
+// It stops at the first error returned by f and returns it.
+func (cv Value) Walk(f func(path string, v Value) error) error {
+	for _, key := range cv.Keys() {
+		v := NewValue(cv.provider, key, nil, false, Invalid, nil)
+		if cv.provider != nil {
+			v = cv.provider.Get(key)
+		}
+
+		if err := f(key, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
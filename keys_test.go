@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	flag "github.com/ogier/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_Keys(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte(`
+db:
+  port: 5432
+  hosts: [a, b]
+  options: {}
+name: gotham
+`))
+
+	assert.Equal(t, []string{"db.hosts.0", "db.hosts.1", "db.options", "db.port", "name"}, p.Get(Root).Keys())
+	assert.Equal(t, []string{"db.hosts.0", "db.hosts.1"}, p.Get("db.hosts").Keys())
+	assert.Equal(t, []string{"name"}, p.Get("name").Keys())
+	assert.Nil(t, p.Get("missing").Keys())
+}
+
+func TestEnumerable_Providers(t *testing.T) {
+	t.Parallel()
+
+	f := flag.NewFlagSet("", flag.PanicOnError)
+	f.String("db.host", "localhost", "")
+	var s StringSlice
+	f.Var(&s, "roles", "")
+
+	tests := map[string]struct {
+		provider Provider
+		keys     []string
+	}{
+		"static": {
+			provider: NewStaticProvider(map[string]interface{}{"b": 1, "a": []int{1, 2}}),
+			keys:     []string{"a.0", "a.1", "b"},
+		},
+		"command line": {
+			provider: NewCommandLineProvider(f, []string{"--roles=a,b"}),
+			keys:     []string{"db.host", "roles.0", "roles.1"},
+		},
+		"mock": {
+			provider: NewMockDynamicProvider(map[string]interface{}{"b.c": 1, "a": "x"}),
+			keys:     []string{"a", "b.c"},
+		},
+		"group": {
+			provider: NewProviderGroup("group",
+				NewStaticProvider(map[string]interface{}{"a": 1, "b": 2}),
+				NewMockDynamicProvider(map[string]interface{}{"c.d": 3, "a": 4}),
+			),
+			keys: []string{"a", "b", "c.d"},
+		},
+		"wrappers": {
+			provider: NewReloadable(NewMultiCallbackProvider(newLazyProvider(func() Provider {
+				return NewStaticProvider(map[string]interface{}{"a": 1})
+			}))),
+			keys: []string{"a"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, ok := tt.provider.(Enumerable)
+			require.True(t, ok, "%T doesn't implement Enumerable", tt.provider)
+			assert.Equal(t, tt.keys, tt.provider.Get(Root).Keys())
+		})
+	}
+}
+
+func TestEnumerable_Scoped(t *testing.T) {
+	t.Parallel()
+
+	p := NewScopedProvider("db", NewStaticProvider(map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost", "port": 5432},
+	}))
+
+	assert.Equal(t, []string{"host", "port"}, p.(Enumerable).Keys(Root))
+}
+
+func TestLoader_Keys(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader(func() (Provider, error) {
+			return NewMockDynamicProvider(map[string]interface{}{"flags.dark": true}), nil
+		})
+
+		l.SetDirs(dir)
+		p := l.Load()
+		assert.Equal(t, []string{"db.host", "flags.dark", "replica.host"}, p.Get(Root).Keys())
+	}
+
+	withBase(t, f, "db: {host: localhost}\nreplica: ${ref:db}")
+}
+
+func TestValue_Walk(t *testing.T) {
+	t.Parallel()
+
+	p := NewStaticProvider(map[string]interface{}{"a": 1, "b": map[string]int{"c": 2, "d": 3}})
+
+	var visited []string
+	require.NoError(t, p.Get(Root).Walk(func(path string, v Value) error {
+		visited = append(visited, path+"="+v.String())
+		return nil
+	}))
+
+	assert.Equal(t, []string{"a=1", "b.c=2", "b.d=3"}, visited)
+
+	visited = nil
+	err := p.Get("b").Walk(func(path string, v Value) error {
+		visited = append(visited, path)
+		return errors.New("stop")
+	})
+
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, []string{"b.c"}, visited)
+}
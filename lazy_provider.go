@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "sync"
+
+// lazyProvider creates the underlying provider on the first call.
+type lazyProvider struct {
+	once     sync.Once
+	load     func() Provider
+	provider Provider
+}
+
+func newLazyProvider(load func() Provider) Provider {
+	return &lazyProvider{load: load}
+}
+
+func (p *lazyProvider) get() Provider {
+	p.once.Do(func() { p.provider = p.load() })
+	return p.provider
+}
+
+// Name returns the name of the underlying provider.
+func (p *lazyProvider) Name() string {
+	return p.get().Name()
+}
+
+// Get returns a value from the underlying provider.
+func (p *lazyProvider) Get(key string) Value {
+	return p.get().Get(key)
+}
+
+// Origins returns origins of the key in the underlying provider.
+func (p *lazyProvider) Origins(key string) []Origin {
+	return originsOf(p.get(), key)
+}
+
+func (p *lazyProvider) Keys(key string) []string {
+	return keysOf(p.get(), key)
+}
+
+// RegisterChangeCallback registers the callback in the underlying provider.
+func (p *lazyProvider) RegisterChangeCallback(key string, callback ChangeCallback) error {
+	return p.get().RegisterChangeCallback(key, callback)
+}
+
+// UnregisterChangeCallback removes the callback from the underlying provider.
+func (p *lazyProvider) UnregisterChangeCallback(token string) error {
+	return p.get().UnregisterChangeCallback(token)
+}
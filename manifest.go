@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// AllEnvironments is used to declare files required or optional in every environment.
+const AllEnvironments = ""
+
+// LoadedFile describes a configuration file loaded by a Loader.
+type LoadedFile struct {
+	// Name of the file as it was requested, e.g. "base.yaml".
+	Name string
+
+	// Path of the file the name was resolved to.
+	Path string
+
+	// SHA256 is a hex encoded checksum of the file contents.
+	SHA256 string
+}
+
+// Manifest lists configuration files loaded by a Loader in the load order.
+type Manifest []LoadedFile
+
+// manifestProvider is a provider that remembers the files and variables
+// it was created from.
+type manifestProvider struct {
+	Provider
+
+	manifest  Manifest
+	variables VariableReport
+}
+
+// Origins returns origins of the key in the wrapped provider.
+func (m manifestProvider) Origins(key string) []Origin {
+	return originsOf(m.Provider, key)
+}
+
+func (m manifestProvider) Keys(key string) []string {
+	return keysOf(m.Provider, key)
+}
+
+// namedReader keeps the name and the file info of a file for readers that
+// were read into memory.
+type namedReader struct {
+	io.Reader
+
+	name string
+	info os.FileInfo
+}
+
+func (n namedReader) Name() string { return n.name }
+
+func (n namedReader) Close() error { return nil }
+
+// Stat returns the file info of the file, so values read from it are as old
+// as its modification time.
+func (n namedReader) Stat() (os.FileInfo, error) {
+	if n.info == nil {
+		return nil, fmt.Errorf("no file info for %q", n.name)
+	}
+
+	return n.info, nil
+}
+
+// openFiles resolves and reads the files, adding every file found to the manifest.
+// Files are rendered with the render function, unless it is nil.
+// It returns an error if a file required in the environment is not found.
+func (l *Loader) openFiles(
+	resolver FileResolver,
+	env string,
+	manifest *Manifest,
+	render func(name string, contents []byte) ([]byte, error),
+	files []string,
+) ([]io.ReadCloser, error) {
+	var readers []io.ReadCloser
+	for _, file := range files {
+		reader := resolver.Resolve(file)
+		if reader == nil {
+			if !l.isRequired(env, file) {
+				continue
+			}
+
+			if dirs := l.SearchedDirs(); len(dirs) > 0 {
+				return nil, fmt.Errorf("required config file %q is not found in %v", file, dirs)
+			}
+
+			return nil, fmt.Errorf("required config file %q is not found", file)
+		}
+
+		// Files can't be stat'ed after they are closed.
+		info := fileInfo(reader)
+		contents, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't read config file %q", file)
+		}
+
+		path := readerName(reader)
+		if path == "" {
+			path = file
+		}
+
+		sum := sha256.Sum256(contents)
+		*manifest = append(*manifest, LoadedFile{Name: file, Path: path, SHA256: hex.EncodeToString(sum[:])})
+
+		if render != nil {
+			if contents, err = render(path, contents); err != nil {
+				return nil, err
+			}
+		}
+		readers = append(readers, namedReader{Reader: bytes.NewReader(contents), name: readerName(reader), info: info})
+	}
+
+	return readers, nil
+}
+
+// isRequired returns true if the file was declared required in the environment,
+// declarations for a specific environment take precedence over AllEnvironments.
+func (l *Loader) isRequired(env, file string) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if required, ok := l.requiredFiles[env][file]; ok {
+		return required
+	}
+
+	return l.requiredFiles[AllEnvironments][file]
+}
+
+func (l *Loader) setRequired(env string, required bool, files []string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.requiredFiles == nil {
+		l.requiredFiles = make(map[string]map[string]bool)
+	}
+
+	if l.requiredFiles[env] == nil {
+		l.requiredFiles[env] = make(map[string]bool)
+	}
+
+	for _, file := range files {
+		l.requiredFiles[env][file] = required
+	}
+}
+
+// RequireFiles makes Load and Reload fail if any of the files is not found
+// in the environment. Use AllEnvironments to require files everywhere.
+func (l *Loader) RequireFiles(env string, files ...string) {
+	l.setRequired(env, true, files)
+}
+
+// OptionalFiles declares files optional in the environment, e.g. to allow
+// a missing secrets.yaml in development when it is required in AllEnvironments.
+// Files are optional unless they are required with RequireFiles.
+func (l *Loader) OptionalFiles(env string, files ...string) {
+	l.setRequired(env, false, files)
+}
+
+// Manifest returns configuration files loaded by the last successful Load or Reload call.
+func (l *Loader) Manifest() Manifest {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return append(Manifest(nil), l.manifest...)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checksum(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoader_Manifest(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		secrets := filepath.Join(dir, _secretsFile)
+		require.NoError(t, ioutil.WriteFile(secrets, []byte("password: ${not expanded}"), os.ModePerm))
+		defer os.Remove(secrets)
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		assert.Empty(t, l.Manifest())
+
+		p := l.Load()
+		assert.Equal(t, "${not expanded}", p.Get("password").AsString())
+		assert.Equal(t, Manifest{
+			{Name: _baseFile, Path: filepath.Join(dir, _baseFile), SHA256: checksum("value: base")},
+			{Name: _secretsFile, Path: secrets, SHA256: checksum("password: ${not expanded}")},
+		}, l.Manifest())
+
+		// Origins are kept for files read by the loader.
+		e, err := Explain(p, "value")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, _baseFile), e.Origin.File)
+
+		require.NoError(t, ioutil.WriteFile(secrets, []byte("password: changed"), os.ModePerm))
+		require.NoError(t, l.Reload())
+		assert.Equal(t, checksum("password: changed"), l.Manifest()[1].SHA256)
+	}
+
+	withBase(t, f, "value: base")
+}
+
+func TestLoader_RequiredFiles(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		env := "development"
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetLookupFn(func(key string) (string, bool) {
+			if key == "APP_ENVIRONMENT" {
+				return env, true
+			}
+
+			return "", false
+		})
+
+		l.RequireFiles(AllEnvironments, _baseFile, _secretsFile)
+		l.RequireFiles("production", "production.yaml")
+		l.OptionalFiles("development", _secretsFile)
+
+		p := l.Load()
+		assert.Equal(t, "base", p.Get("value").AsString())
+		assert.Len(t, l.Manifest(), 1)
+
+		env = "staging"
+		assert.Panics(t, func() { l.Load() })
+		assert.EqualError(t, l.Reload(), `required config file "secrets.yaml" is not found in [`+dir+`]`)
+
+		env = "production"
+		secrets := filepath.Join(dir, _secretsFile)
+		require.NoError(t, ioutil.WriteFile(secrets, []byte("password: 123"), os.ModePerm))
+		defer os.Remove(secrets)
+
+		err := l.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `required config file "production.yaml" is not found`)
+		assert.Len(t, l.Manifest(), 1, "failed reload shouldn't change the manifest")
+	}
+
+	withBase(t, f, "value: base")
+}
@@ -21,6 +21,7 @@
 package config
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -32,12 +33,17 @@ type MockDynamicProvider struct {
 	sync.RWMutex
 	data      map[string]interface{}
 	callBacks map[string]ChangeCallback
+
+	// Values are as old as the provider until they are Set.
+	created revision
+	updated map[string]revision
 }
 
 // NewMockDynamicProvider returns a new MockDynamicProvider
 func NewMockDynamicProvider(data map[string]interface{}) *MockDynamicProvider {
 	return &MockDynamicProvider{
-		data: data,
+		data:    data,
+		created: newRevision(),
 	}
 }
 
@@ -52,20 +58,52 @@ func (s *MockDynamicProvider) Get(key string) Value {
 	defer s.RUnlock()
 
 	val, found := s.data[key]
-	return NewValue(s, key, val, found, GetType(val), nil)
+	r, ok := s.updated[key]
+	if !ok {
+		r = s.created
+	}
+
+	// Providers created without NewMockDynamicProvider don't track updates.
+	if r.version == 0 {
+		return NewValue(s, key, val, found, GetType(val), nil)
+	}
+
+	return newValue(s, key, val, found, GetType(val), r)
+}
+
+// Keys returns keys with values that are equal to the key or start with it.
+func (s *MockDynamicProvider) Keys(key string) []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	var lists [][]string
+	for k, v := range s.data {
+		if key == Root || k == key || strings.HasPrefix(k, key+_separator) {
+			lists = append(lists, treeKeys(k, v))
+		}
+	}
+
+	return mergeKeys(lists...)
 }
 
 // Set value to specific key and then calls a corresponding callback.
+// The callback is called without the lock held, so it can read values of the provider.
 func (s *MockDynamicProvider) Set(key string, value interface{}) {
 	s.Lock()
-	defer s.Unlock()
-
 	if s.data == nil {
 		s.data = make(map[string]interface{})
 	}
 
+	if s.updated == nil {
+		s.updated = make(map[string]revision)
+	}
+
 	s.data[key] = value
-	if cb, ok := s.callBacks[key]; ok {
+	s.updated[key] = newRevision()
+	cb, ok := s.callBacks[key]
+	s.Unlock()
+
+	if ok {
 		cb(key, s.Name(), value)
 	}
 }
@@ -110,3 +110,29 @@ func TestMockDynamicProvider_UnregisterChangeCallback(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "there is no registered callback for token: goofy")
 }
+
+func TestMockDynamicProvider_Versions(t *testing.T) {
+	t.Parallel()
+
+	p := NewMockDynamicProvider(map[string]interface{}{"a": 1, "b": 2})
+	a, b := p.Get("a"), p.Get("b")
+	assert.Equal(t, a.Version(), b.Version())
+	assert.Equal(t, a.LastUpdated(), p.Get("a").LastUpdated(), "values shouldn't be stamped on read")
+
+	p.Set("a", 3)
+	updated := p.Get("a")
+	assert.True(t, updated.Version() > a.Version())
+	assert.False(t, updated.LastUpdated().Before(a.LastUpdated()))
+	assert.Equal(t, b.Version(), p.Get("b").Version())
+}
+
+func TestMockDynamicProvider_Untracked(t *testing.T) {
+	t.Parallel()
+
+	p := &MockDynamicProvider{}
+	assert.Zero(t, p.Get("a").Version(), "providers that don't track updates have version zero")
+	assert.Zero(t, NewValue(p, "a", 1, true, Integer, nil).Version())
+
+	p.Set("a", 1)
+	assert.NotZero(t, p.Get("a").Version())
+}
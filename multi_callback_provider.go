@@ -49,6 +49,14 @@ func (s *multiCallbackProvider) Name() string {
 	return fmt.Sprintf("multiCallbackProvider %q", s.Provider.Name())
 }
 
+func (s *multiCallbackProvider) Origins(key string) []Origin {
+	return originsOf(s.Provider, key)
+}
+
+func (s *multiCallbackProvider) Keys(key string) []string {
+	return keysOf(s.Provider, key)
+}
+
 func (s *multiCallbackProvider) RegisterChangeCallback(key string, callback ChangeCallback) error {
 	s.Lock()
 	defer s.Unlock()
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "strings"
+
+// _escape escapes separators and itself in path segments.
+const _escape = `\`
+
+// Path is a key split into segments, e.g. Path{"hosts", "example.com", "port"}.
+// Segments can contain separators, which makes map keys like "example.com"
+// or "10.0.0.1" unambiguous: keys with escaped separators are matched segment
+// by segment instead of with the longest match of dotted keys.
+type Path []string
+
+// ParsePath splits a dotted key into segments. A backslash escapes the next
+// character, e.g. `hosts.example\.com.port` has three segments.
+func ParsePath(key string) Path {
+	if key == Root {
+		return nil
+	}
+
+	if !strings.Contains(key, _escape) {
+		return strings.Split(key, _separator)
+	}
+
+	var res Path
+	var segment strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == _escape[0] && i+1 < len(key):
+			i++
+			segment.WriteByte(key[i])
+		case c == _separator[0]:
+			res = append(res, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(c)
+		}
+	}
+
+	return append(res, segment.String())
+}
+
+// String returns the dotted key for the path with separators in segments
+// escaped, e.g. `hosts.example\.com.port`. Providers accept it in Get.
+func (p Path) String() string {
+	segments := make([]string, len(p))
+	for i, s := range p {
+		segments[i] = escapeKey(s)
+	}
+
+	return strings.Join(segments, _separator)
+}
+
+// escapeKey escapes separators in a single segment of a key.
+func escapeKey(key string) string {
+	if !strings.ContainsAny(key, _separator+_escape) {
+		return key
+	}
+
+	return strings.NewReplacer(_escape, _escape+_escape, _separator, _escape+_separator).Replace(key)
+}
+
+// GetPath returns the value for the path in the provider, e.g.
+// GetPath(p, "hosts", "example.com", "port").
+func GetPath(p Provider, segments ...string) Value {
+	return p.Get(Path(segments).String())
+}
+
+// GetPath returns the value for the path relative to the value.
+func (cv Value) GetPath(segments ...string) Value {
+	return cv.Get(Path(segments).String())
+}
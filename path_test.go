@@ -0,0 +1,120 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Path{
+		"":                   nil,
+		"a":                  {"a"},
+		"a.b.0":              {"a", "b", "0"},
+		`hosts.example\.com`: {"hosts", "example.com"},
+		`paths.c:\\tmp.mode`: {"paths", `c:\tmp`, "mode"},
+		`a..b`:               {"a", "", "b"},
+	}
+
+	for key, path := range tests {
+		assert.Equal(t, path, ParsePath(key), key)
+		assert.Equal(t, key, path.String(), key)
+	}
+
+	assert.Equal(t, Path{"a", "b"}, ParsePath(`\a.b`))
+	assert.Equal(t, Path{`a\`}, ParsePath(`a\`))
+}
+
+var dottedKeysYaml = []byte(`
+hosts:
+  example.com: {port: 443}
+  10.0.0.1: {port: 80}
+  example:
+    org: {port: 8080}
+`)
+
+func TestGetPath(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(dottedKeysYaml)
+	assert.Equal(t, 443, GetPath(p, "hosts", "example.com", "port").Value())
+	assert.Equal(t, 80, GetPath(p, "hosts", "10.0.0.1", "port").Value())
+	assert.Equal(t, 8080, GetPath(p, "hosts", "example", "org", "port").Value())
+	assert.Equal(t, 443, p.Get("hosts").GetPath("example.com", "port").Value())
+	assert.False(t, GetPath(p, "hosts", "example.com", "missing").HasValue())
+}
+
+func TestPath_PopulateMapWithDottedKeys(t *testing.T) {
+	t.Parallel()
+
+	type host struct {
+		Port int `yaml:"port"`
+	}
+
+	var hosts map[string]host
+	p := NewYAMLProviderFromBytes(dottedKeysYaml)
+	require.NoError(t, p.Get("hosts").Populate(&hosts))
+	assert.Equal(t, map[string]host{
+		"example.com": {Port: 443},
+		"10.0.0.1":    {Port: 80},
+		"example":     {},
+	}, hosts)
+}
+
+func TestPath_KeysRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(dottedKeysYaml)
+	keys := p.Get(Root).Keys()
+	assert.Equal(t, []string{
+		`hosts.10\.0\.0\.1.port`,
+		"hosts.example.org.port",
+		`hosts.example\.com.port`,
+	}, keys)
+
+	for _, key := range keys {
+		assert.Equal(t, GetPath(p, ParsePath(key)...).Value(), p.Get(key).Value(), key)
+	}
+
+}
+
+func TestPath_ExactMatch(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte(`
+a.b: {c: 1}
+a:
+  b.c: 2
+  x.y: 3
+`))
+
+	// Dotted keys use the longest match, escaped ones are exact.
+	assert.Equal(t, 1, p.Get("a.b.c").Value())
+	assert.Equal(t, 2, p.Get(`a.b\.c`).Value())
+	assert.Equal(t, 1, p.Get(`a\.b.c`).Value())
+	assert.Equal(t, 3, GetPath(p, "a", "x.y").Value())
+	assert.False(t, p.Get(`a.x\.y.z`).HasValue())
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "fmt"
+
+// An Origin describes a single definition of a configuration value.
+type Origin struct {
+	// Provider is the name of the provider that defined the value.
+	Provider string
+
+	// File the value was read from, empty if the value didn't come from a file.
+	File string
+
+	// Line and Column of the value in the source, both start at 1.
+	// They are zero if the position is unknown.
+	Line   int
+	Column int
+
+	// Value as it was defined, before interpolation.
+	Value interface{}
+
+	// Tag is an application specific YAML tag of the value, e.g. "!env".
+	Tag string
+}
+
+// String returns a human readable location of the definition,
+// e.g. "config/base.yaml:12:7 (yaml)".
+func (o Origin) String() string {
+	switch {
+	case o.File != "" && o.Line > 0:
+		return fmt.Sprintf("%s:%d:%d (%s)", o.File, o.Line, o.Column, o.Provider)
+	case o.File != "":
+		return fmt.Sprintf("%s (%s)", o.File, o.Provider)
+	case o.Line > 0:
+		return fmt.Sprintf("%s:%d:%d", o.Provider, o.Line, o.Column)
+	}
+
+	return o.Provider
+}
+
+// location returns the position of a definition in a file, e.g. "config/base.yaml:12:7",
+// or an empty string if the value didn't come from a file.
+func (o Origin) location() string {
+	switch {
+	case o.File == "":
+		return ""
+	case o.Line > 0:
+		return fmt.Sprintf("%s:%d:%d", o.File, o.Line, o.Column)
+	}
+
+	return o.File
+}
+
+// An Explainer is a Provider that knows where its values were defined.
+type Explainer interface {
+	// Origins returns all definitions of the value for the key,
+	// starting with the lowest priority one.
+	Origins(key string) []Origin
+}
+
+// Provenance explains where a configuration value came from.
+type Provenance struct {
+	Key   string
+	Value interface{}
+
+	// Origin is the definition of the value in use.
+	Origin Origin
+
+	// Overridden are lower priority definitions replaced by the Origin,
+	// starting with the most recent one.
+	Overridden []Origin
+}
+
+// Explain returns provenance of the value for the key in the provider p.
+// Providers that don't implement Explainer are reported as a single origin
+// with the provider name.
+func Explain(p Provider, key string) (Provenance, error) {
+	v := p.Get(key)
+	origins := originsOf(p, key)
+	if !v.HasValue() || len(origins) == 0 {
+		return Provenance{}, fmt.Errorf("value for the key %q is not defined", key)
+	}
+
+	res := Provenance{
+		Key:    key,
+		Value:  v.Value(),
+		Origin: origins[len(origins)-1],
+	}
+
+	for i := len(origins) - 2; i >= 0; i-- {
+		res.Overridden = append(res.Overridden, origins[i])
+	}
+
+	return res, nil
+}
+
+// originsOf returns origins of the key in p, falling back to the provider name
+// for providers that don't implement Explainer.
+func originsOf(p Provider, key string) []Origin {
+	if p == nil {
+		return nil
+	}
+
+	if e, ok := p.(Explainer); ok {
+		return e.Origins(key)
+	}
+
+	if v := p.Get(key); v.HasValue() && !v.IsDefault() {
+		return []Origin{{Provider: p.Name(), Value: v.Value()}}
+	}
+
+	return nil
+}
+
+// renameOrigins sets the provider name for all origins, it is used by
+// providers that wrap other providers.
+func renameOrigins(origins []Origin, name string) []Origin {
+	for i := range origins {
+		origins[i].Provider = name
+	}
+
+	return origins
+}
+
+// generatedOrigins renames origins of values that were marshaled to YAML and
+// parsed back, e.g. by static providers. Their positions point to the
+// generated YAML and not to a file, so they are dropped.
+func generatedOrigins(origins []Origin, name string) []Origin {
+	for i := range origins {
+		origins[i].Line, origins[i].Column = 0, 0
+	}
+
+	return renameOrigins(origins, name)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrigin_String(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Origin{
+		"config/base.yaml:3:7 (yaml)": {Provider: "yaml", File: "config/base.yaml", Line: 3, Column: 7},
+		"config/base.yaml (yaml)":     {Provider: "yaml", File: "config/base.yaml"},
+		"yaml:3:7":                    {Provider: "yaml", Line: 3, Column: 7},
+		"cmd":                         {Provider: "cmd"},
+	}
+
+	for expected, o := range tests {
+		assert.Equal(t, expected, o.String())
+	}
+}
+
+func TestExplain_YAMLFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestExplain_YAMLFiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, _baseFile)
+	require.NoError(t, ioutil.WriteFile(base, []byte(`
+db:
+  host: localhost
+  port: 5432
+`), os.ModePerm))
+
+	prod := filepath.Join(dir, "production.yaml")
+	require.NoError(t, ioutil.WriteFile(prod, []byte(`
+db:
+  host: db.prod
+`), os.ModePerm))
+
+	secrets := filepath.Join(dir, _secretsFile)
+	require.NoError(t, ioutil.WriteFile(secrets, []byte(`
+db:
+  password: hunter2
+`), os.ModePerm))
+
+	l := NewLoader()
+	l.SetDirs(dir)
+	l.SetLookupFn(func(key string) (string, bool) {
+		if key == "APP_ENVIRONMENT" {
+			return "production", true
+		}
+
+		return "", false
+	})
+
+	p := l.Load()
+
+	host, err := Explain(p, "db.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.host", host.Key)
+	assert.Equal(t, "db.prod", host.Value)
+	assert.Equal(t, Origin{Provider: "yaml", File: prod, Line: 3, Column: 9, Value: "db.prod"}, host.Origin)
+	assert.Equal(t, []Origin{{Provider: "yaml", File: base, Line: 3, Column: 9, Value: "localhost"}}, host.Overridden)
+
+	port, err := Explain(p, "db.port")
+	require.NoError(t, err)
+	assert.Equal(t, base, port.Origin.File)
+	assert.Equal(t, 4, port.Origin.Line)
+	assert.Empty(t, port.Overridden)
+
+	password, err := Explain(p, "db.password")
+	require.NoError(t, err)
+	assert.Equal(t, secrets, password.Origin.File)
+
+	db, err := Explain(p, "db")
+	require.NoError(t, err)
+	assert.Equal(t, secrets, db.Origin.File)
+	assert.Len(t, db.Overridden, 2)
+
+	_, err = Explain(p, "db.user")
+	assert.EqualError(t, err, `value for the key "db.user" is not defined`)
+}
+
+func TestExplain_ProviderGroup(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockDynamicProvider(map[string]interface{}{"hero": "Batman"})
+	p := NewProviderGroup("global",
+		NewYAMLProviderFromBytes([]byte("hero: Robin\nvillain: Joker")),
+		NewStaticProvider(map[string]string{"hero": "Batgirl"}),
+		NewYAMLProviderFromBytes([]byte("hero: ~")),
+		mock,
+	)
+
+	hero, err := Explain(p, "hero")
+	require.NoError(t, err)
+	assert.Equal(t, "Batman", hero.Value)
+	assert.Equal(t, Origin{Provider: "MockDynamicProvider", Value: "Batman"}, hero.Origin)
+	assert.Equal(t, []Origin{
+		{Provider: "static", Value: "Batgirl"},
+		{Provider: "yaml", Line: 1, Column: 7, Value: "Robin"},
+	}, hero.Overridden)
+
+	villain, err := Explain(NewScopedProvider("villain", p), Root)
+	require.NoError(t, err)
+	assert.Equal(t, Origin{Provider: "yaml", Line: 2, Column: 10, Value: "Joker"}, villain.Origin)
+}
+
+func TestExplain_StaticProvider(t *testing.T) {
+	t.Parallel()
+
+	p := NewStaticProvider(map[string]interface{}{"db": map[string]string{"host": "localhost"}})
+	e, err := Explain(p, "db.host")
+	require.NoError(t, err)
+	assert.Equal(t, Origin{Provider: "static", Value: "localhost"}, e.Origin, "positions in generated YAML are meaningless")
+	assert.Equal(t, "static", e.Origin.String())
+}
+
+func TestExplain_Wrappers(t *testing.T) {
+	t.Parallel()
+
+	yaml := NewYAMLProviderFromBytes([]byte("a: b"))
+	expected := Origin{Provider: "yaml", Line: 1, Column: 4, Value: "b"}
+	for _, p := range []Provider{
+		NewMultiCallbackProvider(yaml),
+		NewReloadable(yaml),
+		NewCachedProvider(yaml),
+	} {
+		e, err := Explain(p, "a")
+		require.NoError(t, err)
+		assert.Equal(t, expected, e.Origin)
+	}
+}
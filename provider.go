@@ -20,6 +20,8 @@
 
 package config
 
+import "strings"
+
 // ChangeCallback is called for updates of configuration data
 type ChangeCallback func(key string, provider string, data interface{})
 
@@ -77,6 +79,26 @@ func (sp scopedProvider) RegisterChangeCallback(key string, callback ChangeCallb
 	return sp.Provider.RegisterChangeCallback(sp.addPrefix(key), callback)
 }
 
+// Origins returns origins of the key in the underlying provider
+func (sp scopedProvider) Origins(key string) []Origin {
+	return originsOf(sp.Provider, sp.addPrefix(key))
+}
+
+// Keys returns keys relative to the scope.
+func (sp scopedProvider) Keys(key string) []string {
+	keys := keysOf(sp.Provider, sp.addPrefix(key))
+	res := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == sp.prefix {
+			continue
+		}
+
+		res = append(res, strings.TrimPrefix(k, sp.prefix+_separator))
+	}
+
+	return res
+}
+
 // UnregisterChangeCallback un registers a callback in the underlying provider
 func (sp scopedProvider) UnregisterChangeCallback(key string) error {
 	return sp.Provider.UnregisterChangeCallback(sp.addPrefix(key))
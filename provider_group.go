@@ -37,15 +37,29 @@ func NewProviderGroup(name string, providers ...Provider) Provider {
 func (p providerGroup) Get(key string) Value {
 	// loop through the providers and return the value defined by the highest priority provider
 	var res interface{}
-	found := false
+	var latest revision
+	found, deletions := false, false
 	for _, provider := range p.providers {
-		if val := provider.Get(key); val.HasValue() && !val.IsDefault() {
-			res = mergeMaps(res, val.value)
+		val := provider.Get(key)
+		if isDeleted(val.value) {
+			// A deletion removes values of lower priority providers.
+			res, found, deletions = val.value, false, false
+			latest = latest.latest(val.revision())
+			continue
+		}
+
+		if val.HasValue() && !val.IsDefault() {
+			// Maps are copied, because mergeMaps modifies its destination.
+			res = mergeMaps(res, copyMaps(val.value))
+			latest = latest.latest(val.revision())
 			found = true
+			deletions = deletions || val.deletions
 		}
 	}
 
-	cv := NewValue(p, key, res, found, GetType(res), nil)
+	// The value is as new as the latest layer that contributed to it.
+	cv := newValue(p, key, res, found, GetType(res), latest)
+	cv.deletions = deletions
 
 	// here we add a new root, which defines the "scope" at which
 	// Populates will look for values.
@@ -53,6 +67,45 @@ func (p providerGroup) Get(key string) Value {
 	return cv
 }
 
+// Origins returns definitions of the key from all providers in the group
+// that contributed to the value, starting with the lowest priority provider.
+func (p providerGroup) Origins(key string) []Origin {
+	var res []Origin
+	for _, provider := range p.providers {
+		val := provider.Get(key)
+		if isDeleted(val.value) {
+			res = nil
+			continue
+		}
+
+		// Providers with nil values don't override lower priority values, see mergeMaps.
+		if val.HasValue() && !val.IsDefault() && (val.Value() != nil || len(res) == 0) {
+			res = append(res, originsOf(provider, key)...)
+		}
+	}
+
+	return res
+}
+
+// Keys returns keys from all providers in the group, because all of them
+// are available with Get, except keys deleted by higher priority providers.
+func (p providerGroup) Keys(key string) []string {
+	lists := make([][]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		lists = append(lists, keysOf(provider, key))
+	}
+
+	keys := mergeKeys(lists...)
+	res := keys[:0]
+	for _, k := range keys {
+		if p.Get(k).HasValue() {
+			res = append(res, k)
+		}
+	}
+
+	return res
+}
+
 func (p providerGroup) Name() string {
 	return p.name
 }
@@ -142,3 +142,71 @@ func TestProviderGroup_GetChecksAllProviders(t *testing.T) {
 	require.NoError(t, pg.Get(Root).Populate(&svc))
 	assert.Equal(t, map[string]string{"name": "fx", "owner": "tst@example.com", "desc": "test"}, svc)
 }
+
+func TestProviderGroup_GetDoesNotModifyProviders(t *testing.T) {
+	t.Parallel()
+
+	fst := NewYAMLProviderFromBytes([]byte("a:\n  b: 1"))
+	snd := NewYAMLProviderFromBytes([]byte("a:\n  c: 2"))
+	pg := NewProviderGroup("group", fst, snd)
+
+	assert.Equal(t, map[interface{}]interface{}{"b": 1, "c": 2}, pg.Get("a").Value())
+	assert.Equal(t, map[interface{}]interface{}{"b": 1}, fst.Get("a").Value())
+	assert.Equal(t, map[interface{}]interface{}{"c": 2}, snd.Get("a").Value())
+}
+
+func TestProviderGroup_Versions(t *testing.T) {
+	t.Parallel()
+
+	base := NewMockDynamicProvider(map[string]interface{}{"a": 1})
+	override := NewMockDynamicProvider(map[string]interface{}{"b": 2})
+	pg := NewProviderGroup("group", base, override)
+
+	a := pg.Get("a")
+	assert.Equal(t, base.Get("a").Version(), a.Version())
+	assert.Equal(t, base.Get("a").LastUpdated(), a.LastUpdated())
+
+	override.Set("a", 3)
+	updated := pg.Get("a")
+	assert.Equal(t, override.Get("a").Version(), updated.Version())
+	assert.True(t, updated.Version() > a.Version())
+
+	assert.Zero(t, pg.Get("missing").Version())
+}
+
+func TestProviderGroup_Delete(t *testing.T) {
+	t.Parallel()
+
+	base := NewStaticProvider(map[string]interface{}{
+		"db":      map[string]interface{}{"host": "localhost", "port": 5432},
+		"logging": map[string]interface{}{"level": "info"},
+	})
+
+	override := NewYAMLProviderFromBytes([]byte("db:\n  host: !delete\nlogging: !delete"))
+	pg := NewProviderGroup("group", base, override)
+
+	assert.False(t, pg.Get("db.host").HasValue())
+	assert.False(t, pg.Get("logging.level").HasValue())
+	assert.Equal(t, map[interface{}]interface{}{"port": 5432}, pg.Get("db").Value())
+	assert.Equal(t, []string{"db.port"}, pg.Get(Root).Keys())
+	assert.Empty(t, originsOf(pg, "logging"))
+
+	nested := NewProviderGroup("nested", pg, NewStaticProvider(map[string]interface{}{"db": map[string]interface{}{"user": "root"}}))
+	assert.False(t, nested.Get("logging").HasValue(), "deletions should pass through groups")
+	assert.Equal(t, map[interface{}]interface{}{"port": 5432, "user": "root"}, nested.Get("db").Value())
+
+	readded := NewProviderGroup("readded", pg, NewStaticProvider(map[string]interface{}{"logging": map[string]interface{}{"format": "json"}}))
+	assert.Equal(t, map[interface{}]interface{}{"format": "json"}, readded.Get("logging").Value())
+}
+
+func TestProviderGroup_NullDoesNotOverride(t *testing.T) {
+	t.Parallel()
+
+	pg := NewProviderGroup("group",
+		NewYAMLProviderFromBytes([]byte("a: 1")),
+		NewYAMLProviderFromBytes([]byte("a: ~\nb: ~")))
+
+	assert.Equal(t, 1, pg.Get("a").Value())
+	assert.False(t, pg.Get("a").IsNull())
+	assert.True(t, pg.Get("b").IsNull())
+}
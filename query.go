@@ -0,0 +1,304 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Match is a value found by a query with its concrete dotted key.
+type Match struct {
+	Path  string
+	Value Value
+}
+
+// Query returns values from the provider that match the query, see Value.Query.
+func Query(p Provider, query string) ([]Match, error) {
+	return p.Get(Root).Query(query)
+}
+
+// Query returns values under the value that match the query in the order
+// of their keys. A query is a dotted path with a few extensions:
+//
+//	servers.*.port              every element of a map or a sequence
+//	servers[0].port             an element of a sequence, same as servers.0.port
+//	hosts["example.com"].port   a key with dots
+//	clients[?enabled].name      elements with a value that isn't empty, false or zero
+//	clients[?tier==gold].name   elements with a value equal to the text
+//	clients[?tier!=gold].name   elements with a value different from the text
+//
+// Queries work over any Provider, including groups, because they only use
+// Get and Keys of the provider.
+func (cv Value) Query(query string) ([]Match, error) {
+	steps, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p, paths := cv.provider, []string{cv.key}
+	if p == nil {
+		p, paths = NewStaticProvider(cv.Value()), []string{Root}
+	}
+
+	for _, s := range steps {
+		var next []string
+		for _, path := range paths {
+			next = append(next, s.apply(p, path)...)
+		}
+
+		paths = next
+	}
+
+	res := make([]Match, 0, len(paths))
+	for _, path := range paths {
+		res = append(res, Match{Path: path, Value: p.Get(path)})
+	}
+
+	return res, nil
+}
+
+type queryStepKind int
+
+const (
+	_keyStep queryStepKind = iota
+	_wildcardStep
+	_filterStep
+)
+
+type queryStep struct {
+	kind queryStepKind
+	key  string
+
+	// Filter is key op value, e.g. tier==gold, op is empty for truthy checks.
+	op, value string
+}
+
+// apply returns paths the step leads to from the path.
+func (s queryStep) apply(p Provider, path string) []string {
+	switch s.kind {
+	case _keyStep:
+		// Flat providers, e.g. MockDynamicProvider, only have values for leaves.
+		if key := joinKey(path, s.key); p.Get(key).HasValue() || len(keysOf(p, key)) > 0 {
+			return []string{key}
+		}
+
+		return nil
+	case _wildcardStep:
+		return childPaths(p, path)
+	}
+
+	var res []string
+	for _, child := range childPaths(p, path) {
+		if s.matches(p.Get(joinKey(child, s.key))) {
+			res = append(res, child)
+		}
+	}
+
+	return res
+}
+
+func (s queryStep) matches(v Value) bool {
+	if !v.HasValue() {
+		return s.op == "!="
+	}
+
+	switch s.op {
+	case "==":
+		return fmt.Sprint(v.Value()) == s.value
+	case "!=":
+		return fmt.Sprint(v.Value()) != s.value
+	}
+
+	switch val := v.Value().(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case int:
+		return val != 0
+	case float64:
+		return val != 0
+	}
+
+	return true
+}
+
+// childPaths returns paths of children of a map or a sequence. For providers
+// that don't return collections from Get, e.g. MockDynamicProvider, children
+// are found with Keys.
+func childPaths(p Provider, path string) []string {
+	var names []string
+	switch v := p.Get(path).Value().(type) {
+	case map[interface{}]interface{}:
+		for _, k := range sortedKeys(v) {
+			names = append(names, fmt.Sprint(k))
+		}
+	case []interface{}:
+		for i := range v {
+			names = append(names, strconv.Itoa(i))
+		}
+	default:
+		seen := make(map[string]struct{})
+		prefix := addSeparator(path)
+		for _, key := range keysOf(p, path) {
+			if !strings.HasPrefix(key, prefix) || key == path {
+				continue
+			}
+
+			name := ParsePath(key[len(prefix):])[0]
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+
+		sortNames(names)
+	}
+
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = joinKey(path, name)
+	}
+
+	return res
+}
+
+// sortNames sorts names with numbers in numeric order, e.g. 2 before 10.
+func sortNames(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.Atoi(names[i])
+		b, errB := strconv.Atoi(names[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+
+		return names[i] < names[j]
+	})
+}
+
+// parseQuery splits a query into steps.
+func parseQuery(query string) ([]queryStep, error) {
+	var steps []queryStep
+	fail := func(pos int, msg string) ([]queryStep, error) {
+		return nil, fmt.Errorf("invalid query %q at position %d: %s", query, pos, msg)
+	}
+
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '.':
+			if i == 0 || i+1 == len(query) || query[i+1] == '.' || query[i+1] == '[' {
+				return fail(i, "empty key")
+			}
+
+			i++
+		case c == '[':
+			end := closingBracket(query, i)
+			if end < 0 {
+				return fail(i, "unterminated [")
+			}
+
+			s, err := parseBracket(query[i+1 : end])
+			if err != nil {
+				return fail(i, err.Error())
+			}
+
+			steps = append(steps, s)
+			i = end + 1
+			if i < len(query) && query[i] != '.' && query[i] != '[' {
+				return fail(i, "expected . or [ after ]")
+			}
+		default:
+			end := strings.IndexAny(query[i:], ".[")
+			if end < 0 {
+				end = len(query) - i
+			}
+
+			key := query[i : i+end]
+			if key == "*" {
+				steps = append(steps, queryStep{kind: _wildcardStep})
+			} else {
+				steps = append(steps, queryStep{kind: _keyStep, key: key})
+			}
+
+			i += end
+		}
+	}
+
+	return steps, nil
+}
+
+// closingBracket returns the index of ] that closes [ at the start,
+// skipping brackets in quoted keys.
+func closingBracket(query string, start int) int {
+	quoted := false
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ']':
+			if !quoted {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func parseBracket(s string) (queryStep, error) {
+	switch {
+	case s == "*":
+		return queryStep{kind: _wildcardStep}, nil
+	case strings.HasPrefix(s, `"`):
+		key, err := strconv.Unquote(s)
+		if err != nil {
+			return queryStep{}, fmt.Errorf("invalid quoted key %s", s)
+		}
+
+		return queryStep{kind: _keyStep, key: key}, nil
+	case strings.HasPrefix(s, "?"):
+		s = s[1:]
+		for _, op := range []string{"==", "!="} {
+			if i := strings.Index(s, op); i >= 0 {
+				return queryStep{kind: _filterStep, key: s[:i], op: op, value: s[i+len(op):]}, nil
+			}
+		}
+
+		if s == "" {
+			return queryStep{}, fmt.Errorf("empty filter")
+		}
+
+		return queryStep{kind: _filterStep, key: s}, nil
+	}
+
+	if _, err := strconv.Atoi(s); err != nil {
+		return queryStep{}, fmt.Errorf("expected an index, *, a quoted key or a filter, got %q", s)
+	}
+
+	return queryStep{kind: _keyStep, key: s}, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var queryYaml = []byte(`
+servers:
+  - {name: a, port: 80}
+  - {name: b, port: 81}
+  - {name: c}
+clients:
+  batman: {enabled: true, tier: gold}
+  robin: {enabled: false, tier: silver}
+  joker: {tier: gold}
+hosts:
+  example.com: {port: 443}
+`)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(queryYaml)
+	tests := map[string][]string{
+		"servers.*.port":               {"servers.0.port", "servers.1.port"},
+		"servers[*].name":              {"servers.0.name", "servers.1.name", "servers.2.name"},
+		"servers[1].port":              {"servers.1.port"},
+		"servers.1.port":               {"servers.1.port"},
+		"clients[?enabled].tier":       {"clients.batman.tier"},
+		"clients[?tier==gold]":         {"clients.batman", "clients.joker"},
+		"clients[?tier!=gold].enabled": {"clients.robin.enabled"},
+		"clients[?missing!=x].tier":    {"clients.batman.tier", "clients.joker.tier", "clients.robin.tier"},
+		`hosts["example.com"].port`:    {`hosts.example\.com.port`},
+		"servers.5":                    nil,
+		"missing.*":                    nil,
+	}
+
+	for query, expected := range tests {
+		matches, err := Query(p, query)
+		require.NoError(t, err, query)
+
+		var paths []string
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+			assert.True(t, m.Value.HasValue(), query)
+		}
+
+		assert.Equal(t, expected, paths, query)
+	}
+
+	matches, err := Query(p, `hosts["example.com"].port`)
+	require.NoError(t, err)
+	assert.Equal(t, 443, matches[0].Value.Value())
+
+	matches, err = p.Get("servers").Query("*.port")
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "servers.1.port", matches[1].Path)
+	assert.Equal(t, 81, matches[1].Value.Value())
+}
+
+func TestQuery_Group(t *testing.T) {
+	t.Parallel()
+
+	p := NewProviderGroup("group",
+		NewYAMLProviderFromBytes([]byte("servers: [{port: 80}, {port: 81}]")),
+		NewMockDynamicProvider(map[string]interface{}{"flags.dark": true, "flags.beta": false}),
+	)
+
+	matches, err := Query(p, "servers.*.port")
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, 81, matches[1].Value.Value())
+
+	matches, err = Query(p, "flags.*")
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "flags.beta", matches[0].Path)
+	assert.Equal(t, true, matches[1].Value.Value())
+}
+
+func TestQuery_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a..b":  `invalid query "a..b" at position 1: empty key`,
+		".a":    `invalid query ".a" at position 0: empty key`,
+		"a[0":   `invalid query "a[0" at position 1: unterminated [`,
+		"a[x]":  `invalid query "a[x]" at position 1: expected an index, *, a quoted key or a filter, got "x"`,
+		"a[0]b": `invalid query "a[0]b" at position 4: expected . or [ after ]`,
+		`a["b]`: `invalid query "a[\"b]" at position 1: unterminated [`,
+		"a[?]":  `invalid query "a[?]" at position 1: empty filter`,
+	}
+
+	for query, msg := range tests {
+		_, err := Query(NewStaticProvider(nil), query)
+		assert.EqualError(t, err, msg, query)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// _refPrefix starts references to other keys in ${ref:key} expressions.
+const _refPrefix = "ref:"
+
+// parseReferences splits s into literals and ${ref:key} references, variable
+// names of references are keys. The escaped $${ref:key} is a literal ${ref:key}.
+func parseReferences(s string) (interpolation, error) {
+	var res interpolation
+	var literal strings.Builder
+	for {
+		start := strings.Index(s, "${"+_refPrefix)
+		if start == -1 {
+			break
+		}
+
+		if start > 0 && s[start-1] == '$' {
+			literal.WriteString(s[:start-1] + "${" + _refPrefix)
+			s = s[start+2+len(_refPrefix):]
+			continue
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated ${ in %q", s)
+		}
+
+		literal.WriteString(s[:start])
+		if literal.Len() > 0 {
+			res = append(res, fragment{literal: literal.String()})
+			literal.Reset()
+		}
+
+		res = append(res, fragment{variable: &variable{name: s[start+2+len(_refPrefix) : start+end]}})
+		s = s[start+end+1:]
+	}
+
+	literal.WriteString(s)
+	if literal.Len() > 0 {
+		res = append(res, fragment{literal: literal.String()})
+	}
+
+	return res, nil
+}
+
+// refProvider resolves ${ref:key} references in values of the underlying
+// provider against values of the same provider, so a reference always
+// resolves to the value with the highest priority.
+type refProvider struct {
+	Provider
+}
+
+func newRefProvider(p Provider) Provider {
+	return refProvider{Provider: p}
+}
+
+// Get returns a value with all references resolved. If a reference can't be
+// resolved, the returned value is not found and it holds the error.
+func (p refProvider) Get(key string) Value {
+	v := p.Provider.Get(key)
+	if !v.HasValue() {
+		return p.getReferenced(key, v)
+	}
+
+	res, err := p.resolve(key, v.Value(), nil)
+	if err != nil {
+		return NewValue(p, key, err, false, GetType(err), nil)
+	}
+
+	v.value = res
+	v.deletions = false
+	v.Type = GetType(res)
+	v.provider = p
+	if v.root != nil {
+		v.root = p
+	}
+
+	return v
+}
+
+// getReferenced looks for a missing key in the value of the closest defined
+// parent key, if the parent value is a reference, e.g. "a.b" for "a: ${ref:c}".
+func (p refProvider) getReferenced(key string, missing Value) Value {
+	segments := ParsePath(key)
+	for i := len(segments) - 1; i >= 0; i-- {
+		parent, path := segments[:i].String(), segments[i:]
+
+		raw := p.Provider.Get(parent)
+		if !raw.HasValue() {
+			continue
+		}
+
+		if s, ok := raw.Value().(string); !ok || !strings.Contains(s, "${"+_refPrefix) {
+			return missing
+		}
+
+		val, ok := p.Get(parent).Value(), true
+		for _, k := range path {
+			if val, ok = child(val, k); !ok {
+				return missing
+			}
+		}
+
+		return newValue(p, key, val, true, GetType(val), raw.revision())
+	}
+
+	return missing
+}
+
+// child returns a child of a map or a slice.
+func child(value interface{}, key string) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			if fmt.Sprint(k) == key {
+				return item, true
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(v) {
+			return v[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Origins returns origins of the key in the underlying provider.
+func (p refProvider) Origins(key string) []Origin {
+	return originsOf(p.Provider, key)
+}
+
+// Keys expands references to collections, e.g. a: ${ref:b} lists keys of b under a.
+func (r refProvider) Keys(key string) []string {
+	var lists [][]string
+	for _, k := range keysOf(r.Provider, key) {
+		lists = append(lists, treeKeys(k, r.Get(k).Value()))
+	}
+
+	return mergeKeys(lists...)
+}
+
+// check resolves all references in the provider to report cycles and undefined keys.
+func (p refProvider) check() error {
+	_, err := p.resolve(Root, p.Provider.Get(Root).Value(), nil)
+	return err
+}
+
+// resolve returns a copy of the value for the key with resolved references.
+// The chain holds keys of values with references that led to this value.
+func (p refProvider) resolve(key string, value interface{}, chain []string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		// Keys are sorted to report the same cycle every time.
+		for _, k := range sortedKeys(v) {
+			resolved, err := p.resolve(joinKey(key, k), v[k], chain)
+			if err != nil {
+				return nil, err
+			}
+
+			res[k] = resolved
+		}
+
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := p.resolve(joinKey(key, i), item, chain)
+			if err != nil {
+				return nil, err
+			}
+
+			res[i] = resolved
+		}
+
+		return res, nil
+	case string:
+		if !strings.Contains(v, "${"+_refPrefix) {
+			return v, nil
+		}
+
+		refs, err := parseReferences(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't resolve references for the key %q", key)
+		}
+
+		return p.expand(key, refs, append(chain[:len(chain):len(chain)], key))
+	}
+
+	return value, nil
+}
+
+// expand replaces references with values, a single reference is replaced with
+// the referenced value as is, so it can be a number or a map.
+func (p refProvider) expand(key string, refs interpolation, chain []string) (interface{}, error) {
+	var res strings.Builder
+	for _, f := range refs {
+		if f.variable == nil {
+			res.WriteString(f.literal)
+			continue
+		}
+
+		ref := f.variable.name
+		for _, k := range chain {
+			if k == ref || ref == Root || strings.HasPrefix(k, ref+_separator) {
+				return nil, fmt.Errorf("reference cycle: %s -> %s", strings.Join(chain, " -> "), ref)
+			}
+		}
+
+		v := p.Provider.Get(ref)
+		if !v.HasValue() {
+			return nil, fmt.Errorf("key %q referenced by %q is not defined", ref, key)
+		}
+
+		val, err := p.resolve(ref, v.Value(), chain)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(refs) == 1 {
+			return val, nil
+		}
+
+		switch val.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("can't embed %q of type %T in the value for the key %q", ref, val, key)
+		case nil:
+		default:
+			res.WriteString(fmt.Sprint(val))
+		}
+	}
+
+	return res.String(), nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReferences(t *testing.T) {
+	t.Parallel()
+
+	refs, err := parseReferences("http://${ref:db.host}:${ref:db.port}/$${ref:x}")
+	require.NoError(t, err)
+	assert.Equal(t, interpolation{
+		{literal: "http://"},
+		{variable: &variable{name: "db.host"}},
+		{literal: ":"},
+		{variable: &variable{name: "db.port"}},
+		{literal: "/${ref:x}"},
+	}, refs)
+
+	_, err = parseReferences("${ref:db.host")
+	assert.EqualError(t, err, `unterminated ${ in "${ref:db.host"`)
+}
+
+func TestRefProvider(t *testing.T) {
+	t.Parallel()
+
+	p := newRefProvider(NewProviderGroup("global",
+		NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  port: 5432
+  primary:
+    url: postgres://${ref:db.host}:${ref:db.port}/users
+  replica:
+    url: postgres://${ref:db.host}:${ref:db.port}/users?replica
+    port: ${ref:db.port}
+    copy: ${ref:db.primary}
+  literal: $${ref:db.host}
+`)),
+		NewStaticProvider(map[string]string{"db.host": "db.prod"}),
+		NewYAMLProviderFromBytes([]byte("db:\n  host: db.prod")),
+	))
+
+	assert.Equal(t, "postgres://db.prod:5432/users", p.Get("db.primary.url").AsString())
+	assert.Equal(t, "postgres://db.prod:5432/users?replica", p.Get("db.replica.url").AsString())
+	assert.Equal(t, 5432, p.Get("db.replica.port").Value())
+	assert.Equal(t, "${ref:db.host}", p.Get("db.literal").AsString())
+
+	var db struct {
+		Replica struct {
+			Port int
+			Copy struct {
+				URL string `yaml:"url"`
+			}
+		}
+	}
+
+	require.NoError(t, p.Get("db").Populate(&db))
+	assert.Equal(t, 5432, db.Replica.Port)
+	assert.Equal(t, "postgres://db.prod:5432/users", db.Replica.Copy.URL)
+	require.NoError(t, p.(refProvider).check())
+}
+
+func TestRefProvider_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a: ${ref:b}\nb: ${ref:c}\nc: ${ref:a}": "reference cycle: a -> b -> c -> a",
+		"a:\n  b: x${ref:a}":                    "reference cycle: a.b -> a",
+		"a: ${ref:}":                            "reference cycle: a -> ",
+		"a: ${ref:missing}":                     `key "missing" referenced by "a" is not defined`,
+		"a: x${ref:b}\nb: [1]":                  `can't embed "b" of type []interface {} in the value for the key "a"`,
+		"a: ${ref:b":                            `can't resolve references for the key "a": unterminated ${ in "${ref:b"`,
+	}
+
+	for yaml, expected := range tests {
+		p := newRefProvider(NewYAMLProviderFromBytes([]byte(yaml)))
+		assert.EqualError(t, p.(refProvider).check(), expected, yaml)
+
+		v := p.Get("a")
+		assert.False(t, v.HasValue(), yaml)
+	}
+}
+
+func TestLoader_References(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader(func() (Provider, error) {
+			return NewStaticProvider(map[string]string{"host": "${HOST}"}), nil
+		})
+
+		l.SetDirs(dir)
+		l.SetLookupFn(func(key string) (string, bool) {
+			val, ok := map[string]string{"HOST": "gotham", "PORT": "80"}[key]
+			return val, ok
+		})
+
+		p := l.Load()
+		assert.Equal(t, "${HOST}:80", p.Get("url").AsString())
+	}
+
+	withBase(t, f, "host: localhost\nurl: ${ref:host}:${PORT}")
+
+	withBase(t, func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		assert.Panics(t, func() { l.Load() })
+	}, "a: ${ref:b}\nb: ${ref:a}")
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalReloadConfig describes how a Loader reloads configuration on signals.
+type SignalReloadConfig struct {
+	// Signals that trigger a reload, syscall.SIGHUP is used if empty.
+	Signals []os.Signal
+
+	// MinInterval is the minimum time between two reloads. Signals received
+	// sooner are coalesced into a single reload at the end of the interval.
+	MinInterval time.Duration
+
+	// OnReload is called after every reload with its result, a nil error means
+	// the new configuration is in use. If OnReload is nil, failures are logged
+	// with the standard logger.
+	OnReload func(err error)
+}
+
+// ReloadOnSignal starts reloading configuration with Reload every time
+// the process receives one of the configured signals. Subscribers registered with
+// RegisterChangeCallback on the loaded provider are notified about changed keys.
+// Call the returned function to stop listening for signals.
+func (l *Loader) ReloadOnSignal(cfg SignalReloadConfig) (stop func()) {
+	signals := cfg.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	report := cfg.OnReload
+	if report == nil {
+		report = func(err error) {
+			if err != nil {
+				log.Printf("failed to reload configuration: %v", err)
+			}
+		}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		var last time.Time
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+				if pending != nil {
+					// A reload is already scheduled.
+					continue
+				}
+
+				if wait := cfg.MinInterval - time.Since(last); !last.IsZero() && wait > 0 {
+					pending = time.After(wait)
+					continue
+				}
+			case <-pending:
+			}
+
+			pending = nil
+			last = time.Now()
+			report(l.Reload())
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			<-finished
+		})
+	}
+}
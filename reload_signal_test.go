@@ -0,0 +1,128 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build !windows
+// +build !windows
+
+package config
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests in this file send signals to the test process and shouldn't run in parallel.
+
+func waitForReload(t *testing.T, reloads <-chan error) error {
+	select {
+	case err := <-reloads:
+		return err
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "configuration wasn't reloaded")
+		return nil
+	}
+}
+
+func TestLoader_ReloadOnSignal(t *testing.T) {
+	var version int32
+	l := NewLoader(func() (Provider, error) {
+		return NewStaticProvider(map[string]int32{"version": atomic.AddInt32(&version, 1)}), nil
+	})
+
+	p := l.Load()
+	changed := make(chan interface{}, 1)
+	require.NoError(t, p.RegisterChangeCallback("version", func(key string, provider string, data interface{}) {
+		changed <- data
+	}))
+
+	reloads := make(chan error, 10)
+	stop := l.ReloadOnSignal(SignalReloadConfig{
+		OnReload: func(err error) { reloads <- err },
+	})
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.NoError(t, waitForReload(t, reloads))
+	assert.Equal(t, 2, <-changed)
+	assert.Equal(t, 2, p.Get("version").AsInt())
+
+	stop()
+	stop()
+}
+
+func TestLoader_ReloadOnSignalRateLimit(t *testing.T) {
+	var version int32
+	l := NewLoader(func() (Provider, error) {
+		return NewStaticProvider(map[string]int32{"version": atomic.AddInt32(&version, 1)}), nil
+	})
+
+	p := l.Load()
+	reloads := make(chan error, 10)
+	stop := l.ReloadOnSignal(SignalReloadConfig{
+		Signals:     []os.Signal{syscall.SIGUSR1},
+		MinInterval: 200 * time.Millisecond,
+		OnReload:    func(err error) { reloads <- err },
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.NoError(t, waitForReload(t, reloads))
+	assert.Equal(t, 2, p.Get("version").AsInt())
+
+	// Both signals are coalesced into a single reload at the end of the interval.
+	start := time.Now()
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.NoError(t, waitForReload(t, reloads))
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+	assert.Equal(t, 3, p.Get("version").AsInt())
+
+	select {
+	case <-reloads:
+		assert.Fail(t, "signals were not coalesced")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestLoader_ReloadOnSignalFailure(t *testing.T) {
+	l := NewLoader()
+	l.Load()
+
+	fail := errors.New("Dr. Evil was here")
+	l.RegisterValidators(func(Provider) error { return fail })
+
+	reloads := make(chan error, 1)
+	stop := l.ReloadOnSignal(SignalReloadConfig{
+		OnReload: func(err error) { reloads <- err },
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	err := waitForReload(t, reloads)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fail.Error())
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Reloadable is a Provider that delegates all calls to an underlying provider,
+// which can be atomically replaced with Swap. Change callbacks registered on
+// a Reloadable survive swaps and are called for keys whose values were changed
+// by a swap.
+type Reloadable struct {
+	lock      sync.RWMutex
+	provider  Provider
+	callbacks map[string]ChangeCallback
+
+	// Serializes swaps, so callbacks are called in the order of swaps.
+	swap sync.Mutex
+}
+
+var _ Provider = (*Reloadable)(nil)
+
+// NewReloadable returns a Reloadable provider that delegates to p until it is swapped.
+func NewReloadable(p Provider) *Reloadable {
+	if p == nil {
+		panic("Received a nil provider")
+	}
+
+	return &Reloadable{
+		provider:  p,
+		callbacks: make(map[string]ChangeCallback),
+	}
+}
+
+// Current returns the underlying provider.
+func (r *Reloadable) Current() Provider {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.provider
+}
+
+// Name returns the name of the underlying provider.
+func (r *Reloadable) Name() string {
+	return r.Current().Name()
+}
+
+// Get returns a value from the underlying provider.
+func (r *Reloadable) Get(key string) Value {
+	return r.Current().Get(key)
+}
+
+// Origins returns origins of the key in the underlying provider.
+func (r *Reloadable) Origins(key string) []Origin {
+	return originsOf(r.Current(), key)
+}
+
+// Keys returns keys under the key in the underlying provider.
+func (r *Reloadable) Keys(key string) []string {
+	return keysOf(r.Current(), key)
+}
+
+// RegisterChangeCallback registers a callback in the underlying provider and
+// remembers it to carry it over to the next provider on Swap.
+// Only one callback per key is allowed.
+func (r *Reloadable) RegisterChangeCallback(key string, callback ChangeCallback) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.callbacks[key]; ok {
+		return errors.New("callback already registered for the key: " + key)
+	}
+
+	if err := r.provider.RegisterChangeCallback(key, callback); err != nil {
+		return err
+	}
+
+	r.callbacks[key] = callback
+	return nil
+}
+
+// UnregisterChangeCallback removes a callback associated with a token.
+func (r *Reloadable) UnregisterChangeCallback(token string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.callbacks, token)
+	return r.provider.UnregisterChangeCallback(token)
+}
+
+// Swap atomically replaces the underlying provider with p. Registered callbacks
+// are moved to p and called for every key whose value in p differs from the
+// value in the replaced provider. If p fails to register any of the callbacks,
+// the underlying provider is left intact and the error is returned.
+func (r *Reloadable) Swap(p Provider) error {
+	if p == nil {
+		return errors.New("can't swap to a nil provider")
+	}
+
+	r.swap.Lock()
+	defer r.swap.Unlock()
+
+	r.lock.Lock()
+	keys := make([]string, 0, len(r.callbacks))
+	for key := range r.callbacks {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	old := r.provider
+	for i, key := range keys {
+		// Providers shared by both allow only one callback per key,
+		// so callbacks are removed from the old provider first.
+		old.UnregisterChangeCallback(key)
+		if err := p.RegisterChangeCallback(key, r.callbacks[key]); err != nil {
+			for _, registered := range keys[:i] {
+				p.UnregisterChangeCallback(registered)
+			}
+
+			for _, moved := range keys[:i+1] {
+				old.RegisterChangeCallback(moved, r.callbacks[moved])
+			}
+
+			r.lock.Unlock()
+			return errors.Wrapf(err, "can't move a callback for the key %q", key)
+		}
+	}
+
+	r.provider = p
+	callbacks := make(map[string]ChangeCallback, len(r.callbacks))
+	for key, cb := range r.callbacks {
+		callbacks[key] = cb
+	}
+
+	r.lock.Unlock()
+
+	// Callbacks are called without holding the lock, so they can read new values.
+	for _, key := range keys {
+		before, after := old.Get(key), p.Get(key)
+		if before.HasValue() != after.HasValue() || !reflect.DeepEqual(before.Value(), after.Value()) {
+			callbacks[key](key, p.Name(), after.Value())
+		}
+	}
+
+	return nil
+}
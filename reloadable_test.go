@@ -0,0 +1,214 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReloadable_NilProvider(t *testing.T) {
+	t.Parallel()
+	assert.Panics(t, func() { NewReloadable(nil) })
+}
+
+func TestReloadable_Swap(t *testing.T) {
+	t.Parallel()
+
+	r := NewReloadable(NewStaticProvider(map[string]string{"hero": "Batman", "city": "Gotham"}))
+	assert.Equal(t, "static", r.Name())
+
+	changes := map[string]interface{}{}
+	cb := func(key string, provider string, data interface{}) {
+		changes[key] = data
+	}
+
+	require.NoError(t, r.RegisterChangeCallback("hero", cb))
+	require.NoError(t, r.RegisterChangeCallback("city", cb))
+	require.NoError(t, r.RegisterChangeCallback("villain", cb))
+	assert.EqualError(t, r.RegisterChangeCallback("hero", cb), "callback already registered for the key: hero")
+
+	require.NoError(t, r.Swap(NewStaticProvider(map[string]string{"hero": "Robin", "city": "Gotham"})))
+	assert.Equal(t, "Robin", r.Get("hero").AsString())
+	assert.Equal(t, map[string]interface{}{"hero": "Robin"}, changes)
+
+	require.NoError(t, r.UnregisterChangeCallback("hero"))
+	require.NoError(t, r.Swap(NewStaticProvider(map[string]string{"hero": "Batgirl", "villain": "Joker"})))
+	assert.Equal(t, map[string]interface{}{"hero": "Robin", "city": nil, "villain": "Joker"}, changes)
+}
+
+func TestReloadable_SwapNil(t *testing.T) {
+	t.Parallel()
+
+	r := NewReloadable(NewStaticProvider(nil))
+	assert.Error(t, r.Swap(nil))
+}
+
+func TestReloadable_SwapCallbackFailure(t *testing.T) {
+	t.Parallel()
+
+	r := NewReloadable(NewStaticProvider(map[string]string{"a": "old"}))
+	nop := func(key string, provider string, data interface{}) {}
+	require.NoError(t, r.RegisterChangeCallback("a", nop))
+	require.NoError(t, r.RegisterChangeCallback("b", nop))
+
+	mock := NewMockDynamicProvider(map[string]interface{}{"a": "new"})
+	require.NoError(t, mock.RegisterChangeCallback("b", nop))
+
+	err := r.Swap(mock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't move a callback for the key "b"`)
+	assert.Equal(t, "old", r.Get("a").AsString())
+
+	// Registration for "a" was rolled back.
+	assert.Error(t, mock.UnregisterChangeCallback("a"))
+}
+
+func TestReloadable_SwapRestoresCallbacks(t *testing.T) {
+	t.Parallel()
+
+	old := NewMockDynamicProvider(map[string]interface{}{"a": "old"})
+	r := NewReloadable(old)
+
+	var updates []interface{}
+	require.NoError(t, r.RegisterChangeCallback("a", func(key string, provider string, data interface{}) {
+		updates = append(updates, data)
+	}))
+
+	failing := NewMockDynamicProvider(nil)
+	require.NoError(t, failing.RegisterChangeCallback("a", func(string, string, interface{}) {}))
+	require.Error(t, r.Swap(failing))
+
+	old.Set("a", "changed")
+	assert.Equal(t, []interface{}{"changed"}, updates, "callbacks should be registered in the old provider")
+}
+
+func TestLoader_ReloadSharedDynamicProvider(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMockDynamicProvider(map[string]interface{}{"x": 1})
+	l := NewLoader()
+	l.RegisterDynamicProviders(func(Provider) (Provider, error) { return mock, nil })
+
+	p := l.Load()
+	var updates []interface{}
+	require.NoError(t, p.RegisterChangeCallback("x", func(key string, provider string, data interface{}) {
+		updates = append(updates, data)
+	}))
+
+	require.NoError(t, l.Reload())
+	require.NoError(t, l.Reload())
+
+	mock.Set("x", 2)
+	assert.Equal(t, []interface{}{2}, updates)
+}
+
+func TestLoader_ReloadBeforeLoad(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader()
+	assert.EqualError(t, l.Reload(), "can't reload configuration before it is loaded")
+}
+
+func TestLoader_Reload(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestLoader_Reload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, _baseFile)
+	require.NoError(t, ioutil.WriteFile(base, []byte("name: batman"), os.ModePerm))
+
+	l := NewLoader()
+	l.SetDirs(dir)
+	l.RegisterValidators(func(p Provider) error {
+		var cfg struct {
+			Name string `yaml:"name" validate:"nonzero"`
+		}
+
+		return p.Get(Root).Populate(&cfg)
+	})
+
+	p := l.Load()
+	require.Equal(t, "batman", p.Get("name").AsString())
+
+	var updates []interface{}
+	require.NoError(t, p.RegisterChangeCallback("name", func(key string, provider string, data interface{}) {
+		updates = append(updates, data)
+	}))
+
+	require.NoError(t, ioutil.WriteFile(base, []byte("name: robin"), os.ModePerm))
+	require.NoError(t, l.Reload())
+	assert.Equal(t, "robin", p.Get("name").AsString())
+	assert.Equal(t, []interface{}{"robin"}, updates)
+
+	t.Run("validation failure", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(base, []byte("name: \"\""), os.ModePerm))
+		err := l.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "configuration validation failed")
+		assert.Equal(t, "robin", p.Get("name").AsString())
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(base, []byte("\t"), os.ModePerm))
+		err := l.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "in file: \""+base+"\"")
+		assert.Equal(t, "robin", p.Get("name").AsString())
+	})
+
+	assert.Equal(t, []interface{}{"robin"}, updates)
+}
+
+func TestLoader_LoadPanicsOnValidationError(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader()
+	l.RegisterValidators(func(Provider) error { return errors.New("bad config") })
+	assert.Panics(t, func() { l.Load() })
+}
+
+func TestLoader_ReloadProviderError(t *testing.T) {
+	t.Parallel()
+
+	fail := false
+	l := NewLoader(func() (Provider, error) {
+		if fail {
+			return nil, fmt.Errorf("provider is gone")
+		}
+
+		return NewStaticProvider(map[string]string{"a": "b"}), nil
+	})
+
+	p := l.Load()
+	fail = true
+	assert.EqualError(t, l.Reload(), "provider is gone")
+	assert.Equal(t, "b", p.Get("a").AsString())
+}
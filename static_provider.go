@@ -42,9 +42,14 @@ func NewStaticProvider(data interface{}) Provider {
 
 // NewStaticProviderWithExpand returns a static provider with values replaced by a mapping function.
 func NewStaticProviderWithExpand(data interface{}, mapping func(string) (string, bool)) Provider {
+	// Strings in data are strings after expansion too.
+	p, err := newYAMLProviderWithExpand(yamlParser{}, expander{lookUp: mapping}, toReadCloser(data))
+	if err != nil {
+		panic(err)
+	}
 
 	return staticProvider{
-		Provider: NewYAMLProviderFromReaderWithExpand(mapping, toReadCloser(data)),
+		Provider: NewCachedProvider(p),
 	}
 }
 
@@ -59,6 +64,14 @@ func (staticProvider) Name() string {
 	return "static"
 }
 
+func (s staticProvider) Keys(key string) []string {
+	return keysOf(s.Provider, key)
+}
+
+func (s staticProvider) Origins(key string) []Origin {
+	return generatedOrigins(originsOf(s.Provider, key), s.Name())
+}
+
 func toReadCloser(data interface{}) io.ReadCloser {
 	b, err := yaml.Marshal(data)
 	if err != nil {
@@ -0,0 +1,191 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TagContext describes a YAML scalar with an application specific tag,
+// e.g. !env PORT.
+type TagContext struct {
+	// Tag is the tag of the scalar, e.g. "!env".
+	Tag string
+
+	// Value is the text of the scalar, e.g. "PORT".
+	Value string
+
+	// File the scalar was read from, it is empty for values that
+	// didn't come from a file.
+	File string
+
+	// LookUp returns values of variables, the same way they are looked up
+	// for ${VAR} expressions.
+	LookUp func(name string) (string, bool)
+
+	// Resolver opens files, it is the file resolver of the Loader.
+	// It is nil for YAML providers created outside of the Loader.
+	Resolver FileResolver
+}
+
+// TagHandler resolves a tagged scalar into the value stored in the configuration.
+type TagHandler func(TagContext) (interface{}, error)
+
+// _secretTag marks values that are redacted by Dump.
+const _secretTag = "!secret"
+
+// DefaultTagHandlers returns handlers for the tags the Loader understands:
+//
+//	!env NAME          value of the variable, resolved with the YAML rules, e.g. 8080 is an integer
+//	!secret NAME       same as !env, but Dump always redacts the value
+//	!file path         contents of the file opened with the Loader's FileResolver,
+//	                   relative paths start at the YAML file directory
+//	!base64 text       decoded text
+//	!duration 5m       time.Duration, see time.ParseDuration
+//
+// YAML providers created outside of the Loader only understand !base64 and
+// !duration, so parsing YAML never reads files or variables of the host.
+func DefaultTagHandlers() map[string]TagHandler {
+	res := pureTagHandlers()
+	res["!env"] = envTag
+	res[_secretTag] = envTag
+	res["!file"] = fileTag
+	return res
+}
+
+// pureTagHandlers returns handlers for tags that depend only on the tagged text.
+func pureTagHandlers() map[string]TagHandler {
+	return map[string]TagHandler{
+		"!base64":   base64Tag,
+		"!duration": durationTag,
+	}
+}
+
+func envTag(ctx TagContext) (interface{}, error) {
+	name := strings.TrimSpace(ctx.Value)
+	val, ok := ctx.LookUp(name)
+	if !ok {
+		return nil, fmt.Errorf("variable %q is not set", name)
+	}
+
+	return resolvePlainScalar(val), nil
+}
+
+func fileTag(ctx TagContext) (interface{}, error) {
+	path := strings.TrimSpace(ctx.Value)
+	if !filepath.IsAbs(path) && ctx.File != "" {
+		path = filepath.Join(filepath.Dir(ctx.File), path)
+	}
+
+	var reader io.ReadCloser
+	if ctx.Resolver != nil {
+		reader = ctx.Resolver.Resolve(path)
+	}
+
+	if reader == nil {
+		return nil, fmt.Errorf("file %q not found", path)
+	}
+
+	defer reader.Close()
+
+	contents, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(contents), nil
+}
+
+func base64Tag(ctx TagContext) (interface{}, error) {
+	// Long values are usually split into multiple lines.
+	res, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(ctx.Value), ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(res), nil
+}
+
+func durationTag(ctx TagContext) (interface{}, error) {
+	return time.ParseDuration(strings.TrimSpace(ctx.Value))
+}
+
+// RegisterTagHandlers adds handlers for application specific YAML tags to
+// the default ones or replaces them. Tags without handlers are rejected.
+func (l *Loader) RegisterTagHandlers(handlers map[string]TagHandler) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.tagHandlers == nil {
+		l.tagHandlers = DefaultTagHandlers()
+	}
+
+	for tag, h := range handlers {
+		l.tagHandlers[tag] = h
+	}
+}
+
+func (l *Loader) getTagHandlers() map[string]TagHandler {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if l.tagHandlers == nil {
+		return DefaultTagHandlers()
+	}
+
+	res := make(map[string]TagHandler, len(l.tagHandlers))
+	for tag, h := range l.tagHandlers {
+		res[tag] = h
+	}
+
+	return res
+}
+
+// resolveTag calls the handler for the tag of a scalar.
+func (p yamlParser) resolveTag(tag, value string) (interface{}, error) {
+	tags := p.tags
+	if tags == nil {
+		tags = pureTagHandlers()
+	}
+
+	h, ok := tags[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown tag %q", tag)
+	}
+
+	lookUp := p.lookUp
+	if lookUp == nil {
+		lookUp = func(string) (string, bool) { return "", false }
+	}
+
+	res, err := h(TagContext{Tag: tag, Value: value, File: p.file, LookUp: lookUp, Resolver: p.resolver})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %v", tag, value, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_DefaultTags(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		cert := filepath.Join(dir, "cert.pem")
+		require.NoError(t, ioutil.WriteFile(cert, []byte("CERTIFICATE"), os.ModePerm))
+		defer os.Remove(cert)
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetVariables(map[string]string{"PORT": "8080", "DB_PASSWORD": "hunter2"})
+
+		p := l.Load()
+		assert.Equal(t, 8080, p.Get("port").Value())
+		assert.Equal(t, "CERTIFICATE", p.Get("cert").Value())
+		assert.Equal(t, "gotham", p.Get("name").Value())
+		assert.Equal(t, 5*time.Minute, p.Get("timeout").Value())
+		assert.Equal(t, "hunter2", p.Get("db.pass").Value())
+
+		var timeout time.Duration
+		require.NoError(t, p.Get("timeout").Populate(&timeout))
+		assert.Equal(t, 5*time.Minute, timeout)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, Dumper{RedactKeys: []*regexp.Regexp{}}.Dump(p, buf))
+		assert.Contains(t, buf.String(), "pass: <redacted>")
+		assert.NotContains(t, buf.String(), "hunter2")
+
+		pr, err := Explain(p, "db.pass")
+		require.NoError(t, err)
+		assert.Equal(t, "!secret", pr.Origin.Tag)
+		assert.Equal(t, "DB_PASSWORD", pr.Origin.Value, "origins shouldn't leak secrets")
+	}
+
+	withBase(t, f, `
+port: !env PORT
+cert: !file cert.pem
+name: !base64 Z290aGFt
+timeout: !duration 5m
+db:
+  pass: !secret DB_PASSWORD
+`)
+}
+
+func TestLoader_TagsAreNotInterpolated(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		script := filepath.Join(dir, "script.sh")
+		require.NoError(t, ioutil.WriteFile(script, []byte("cd $HOME"), os.ModePerm))
+		defer os.Remove(script)
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetVariables(map[string]string{"DB_PASS": "pa$word", "GREETING": "${USER}"})
+
+		p := l.Load()
+		assert.Equal(t, "pa$word", p.Get("db.pass").Value())
+		assert.Equal(t, "${USER}", p.Get("greeting").Value())
+		assert.Equal(t, "cd $HOME", p.Get("script").Value())
+	}
+
+	withBase(t, f, `
+db:
+  pass: !secret DB_PASS
+greeting: !env GREETING
+script: !file script.sh
+`)
+}
+
+func TestYAMLParser_TagErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"name: !foo bar":               `base.yaml:1:7: unknown tag "!foo"`,
+		"list: !env [a]":               `base.yaml:1:7: tag "!env" can't be used with collections`,
+		"port: !env UNDEFINED_TAG_VAR": `base.yaml:1:7: !env UNDEFINED_TAG_VAR: variable "UNDEFINED_TAG_VAR" is not set`,
+		"timeout: !duration soon":      `base.yaml:1:10: !duration soon: time: invalid duration`,
+	}
+
+	for doc, msg := range tests {
+		_, _, err := yamlParser{file: "base.yaml", tags: DefaultTagHandlers()}.parse([]byte(doc))
+		require.Error(t, err, doc)
+		assert.True(t, strings.HasPrefix(err.Error(), msg), "%q doesn't start with %q", err.Error(), msg)
+	}
+}
+
+func TestYAMLProvider_HostTagsRequireLoader(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{"a: !file /etc/hostname", "a: !env HOME", "a: !secret HOME"} {
+		assert.Panics(t, func() { NewYAMLProviderFromBytes([]byte(doc)) }, doc)
+	}
+
+	p := NewYAMLProviderFromBytes([]byte("name: !base64 Z290aGFt\ntimeout: !duration 1s"))
+	assert.Equal(t, "gotham", p.Get("name").Value())
+	assert.Equal(t, time.Second, p.Get("timeout").Value())
+}
+
+func TestYAMLProvider_DurationTag(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte("timeout: !duration 5m"))
+	assert.Equal(t, Duration, p.Get("timeout").Type)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Dump(p, buf, YAMLFormat))
+	assert.Equal(t, "timeout: 5m0s\n", buf.String())
+
+	out, err := Export(p, JSONFormat)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"timeout": "5m0s"}`, string(out))
+
+	out, err = Export(p, YAMLFormat)
+	require.NoError(t, err)
+	assert.Equal(t, "timeout: 5m0s\n", string(out))
+
+	var timeout time.Duration
+	require.NoError(t, NewYAMLProviderFromBytes(out).Get("timeout").Populate(&timeout))
+	assert.Equal(t, 5*time.Minute, timeout, "exported durations should be read back")
+}
+
+func TestLoader_FileTagResolver(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader()
+	l.SetFileResolver(mapResolver{
+		"base.yaml":      "cert: !file certs/cert.pem\nmissing: !file nope.pem",
+		"certs/cert.pem": "CERTIFICATE",
+	})
+
+	assert.Panics(t, func() { l.Load() })
+
+	l.SetFileResolver(mapResolver{
+		"base.yaml":      "cert: !file certs/cert.pem",
+		"certs/cert.pem": "CERTIFICATE",
+	})
+
+	assert.Equal(t, "CERTIFICATE", l.Load().Get("cert").Value())
+}
+
+// mapResolver resolves files from memory.
+type mapResolver map[string]string
+
+func (m mapResolver) Resolve(file string) io.ReadCloser {
+	contents, ok := m[file]
+	if !ok {
+		return nil
+	}
+
+	return namedReader{Reader: strings.NewReader(contents), name: file}
+}
+
+func TestLoader_RegisterTagHandlers(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.RegisterTagHandlers(map[string]TagHandler{
+			"!upper": func(ctx TagContext) (interface{}, error) {
+				return strings.ToUpper(ctx.Value), nil
+			},
+		})
+
+		p := l.Load()
+		assert.Equal(t, "BATMAN", p.Get("hero").Value())
+		assert.Equal(t, 10*time.Second, p.Get("timeout").Value())
+
+		withoutHandler := NewLoader()
+		withoutHandler.SetDirs(dir)
+		assert.Panics(t, func() { withoutHandler.Load() })
+	}
+
+	withBase(t, f, "hero: !upper batman\ntimeout: !duration 10s")
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"text/template"
+)
+
+// TemplateData is available in config file templates as the dot,
+// e.g. {{ .Environment }}, see Loader.EnableTemplates.
+type TemplateData struct {
+	// Environment is the environment returned by Loader.Environment.
+	Environment string
+
+	// Hostname is the value of the ${prefix}_HOSTNAME variable if it is set,
+	// and the host name reported by the kernel otherwise.
+	Hostname string
+}
+
+// EnableTemplates makes the loader render config files that are interpolated
+// with text/template before they are parsed, e.g. to generate a list of
+// shards in a loop. Templates get TemplateData as the dot, the env function
+// returns a variable from the same sources as ${VAR} expressions or an empty
+// string, and funcs are added on top of it. Static config files, e.g.
+// secrets.yaml, are not rendered.
+func (l *Loader) EnableTemplates(funcs template.FuncMap) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.templateFuncs = template.FuncMap{}
+	for name, f := range funcs {
+		l.templateFuncs[name] = f
+	}
+}
+
+// templateRenderer returns a function to render config files,
+// it returns nil if templates are not enabled.
+func (l *Loader) templateRenderer(lookUp lookUpFunc) func(name string, contents []byte) ([]byte, error) {
+	l.lock.RLock()
+	userFuncs := l.templateFuncs
+	l.lock.RUnlock()
+
+	if userFuncs == nil {
+		return nil
+	}
+
+	funcs := template.FuncMap{
+		"env": func(name string) string {
+			val, _ := lookUp(name)
+			return val
+		},
+	}
+
+	for name, f := range userFuncs {
+		funcs[name] = f
+	}
+
+	hostname, ok := l.lookUp(l.EnvironmentPrefix() + _hostname)
+	if !ok {
+		hostname, _ = os.Hostname()
+	}
+
+	data := TemplateData{
+		Environment: l.Environment(),
+		Hostname:    hostname,
+	}
+
+	// Templates are named after files, so errors have file names and lines.
+	return func(name string, contents []byte) ([]byte, error) {
+		t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(contents))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, data); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Templates(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetVariables(map[string]string{"SHARDS": "3", "REGION": "west"})
+
+		l.EnableTemplates(template.FuncMap{
+			"upper": strings.ToUpper,
+			"seq": func(n string) ([]int, error) {
+				count, err := strconv.Atoi(n)
+				return make([]int, count), err
+			},
+		})
+		p := l.Load()
+
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+
+		assert.Equal(t, "development", p.Get("env").AsString())
+		assert.Equal(t, hostname, p.Get("host").AsString())
+		assert.Equal(t, "WEST", p.Get("region").AsString())
+		assert.Equal(t, []interface{}{"shard-0", "shard-1", "shard-2"}, p.Get("shards").Value())
+		assert.True(t, p.Get("debug").AsBool())
+		assert.Contains(t, l.Variables().Referenced, "SHARDS")
+
+		l.SetLookupFn(func(key string) (string, bool) { return "batcave", key == "APP_HOSTNAME" })
+		assert.Equal(t, "batcave", l.Load().Get("host").AsString())
+	}
+
+	withBase(t, f, strings.Join([]string{
+		"env: {{ .Environment }}",
+		"host: {{ .Hostname }}",
+		`region: {{ env "REGION" | upper }}`,
+		"shards:",
+		`{{- range $i, $_ := seq (env "SHARDS") }}`,
+		"  - shard-{{ $i }}",
+		"{{- end }}",
+		`{{ if eq .Environment "development" }}debug: true{{ end }}`,
+	}, "\n"))
+}
+
+func TestLoader_TemplatesDisabled(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		assert.Equal(t, "{{ .Environment }}", l.Load().Get("env").AsString())
+	}
+
+	withBase(t, f, `env: "{{ .Environment }}"`)
+}
+
+func TestLoader_TemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.EnableTemplates(nil)
+
+		_, err := l.YamlProvider()()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "template: "+filepath.Join(dir, _baseFile)+":2:")
+	}
+
+	withBase(t, f, "a: b\nc: {{ .Missing }}")
+}
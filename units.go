@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes that can be written with a unit, e.g. "512MiB"
+// or "1.5GB". KB, MB, GB, TB and PB are powers of 1000, KiB, MiB, GiB, TiB
+// and PiB are powers of 1024.
+type ByteSize uint64
+
+// Byte sizes.
+const (
+	Byte ByteSize = 1
+	KiB           = 1024 * Byte
+	MiB           = 1024 * KiB
+	GiB           = 1024 * MiB
+	TiB           = 1024 * GiB
+	PiB           = 1024 * TiB
+)
+
+var _byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   1000,
+	"kb":  1000,
+	"ki":  KiB,
+	"kib": KiB,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mi":  MiB,
+	"mib": MiB,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gi":  GiB,
+	"gib": GiB,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"ti":  TiB,
+	"tib": TiB,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"pi":  PiB,
+	"pib": PiB,
+}
+
+var _byteSize = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// UnmarshalText parses a byte size, e.g. "512MiB".
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	m := _byteSize.FindStringSubmatch(s)
+	if m == nil {
+		return fmt.Errorf("invalid byte size %q", s)
+	}
+
+	unit, ok := _byteUnits[strings.ToLower(m[2])]
+	if !ok {
+		return fmt.Errorf("unknown unit %q in byte size %q", m[2], s)
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return err
+	}
+
+	size := n * float64(unit)
+	if size >= math.MaxUint64 {
+		return fmt.Errorf("byte size %q is too large", s)
+	}
+
+	*b = ByteSize(size)
+	return nil
+}
+
+// String returns the size with the largest binary unit that represents
+// it exactly, e.g. "512MiB".
+func (b ByteSize) String() string {
+	units := []struct {
+		name string
+		size ByteSize
+	}{{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}}
+
+	for _, u := range units {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// Percent is a fraction that can be written as a percentage, e.g. "75%" is 0.75.
+// Numbers without the percent sign are fractions.
+type Percent float64
+
+// UnmarshalText parses a percentage, e.g. "75%" or "0.75".
+func (p *Percent) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+		scale = 100
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid percentage %q", string(text))
+	}
+
+	*p = Percent(f / scale)
+	return nil
+}
+
+// String returns the value as a percentage, e.g. "75%".
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p)*100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]ByteSize{
+		"0":       0,
+		"512":     512,
+		"512B":    512,
+		"1k":      1000,
+		"1KiB":    KiB,
+		"2 MiB":   2 * MiB,
+		"1.5GB":   1500 * 1000 * 1000,
+		"0.5gib":  512 * MiB,
+		"3TiB":    3 * TiB,
+		"1PB":     1000 * 1000 * 1000 * 1000 * 1000,
+		" 1 pi  ": PiB,
+	}
+
+	for text, expected := range tests {
+		var b ByteSize
+		require.NoError(t, b.UnmarshalText([]byte(text)), text)
+		assert.Equal(t, expected, b, text)
+	}
+
+	for _, text := range []string{"", "MiB", "-1KiB", "1 lightyear", "99999999PiB"} {
+		var b ByteSize
+		assert.Error(t, b.UnmarshalText([]byte(text)), text)
+	}
+
+	assert.Equal(t, "512MiB", (512 * MiB).String())
+	assert.Equal(t, "1536KiB", (1536 * KiB).String())
+	assert.Equal(t, "1000B", ByteSize(1000).String())
+	assert.Equal(t, "0B", ByteSize(0).String())
+}
+
+func TestPercent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Percent{
+		"75%":   0.75,
+		"12.5%": 0.125,
+		"0.25":  0.25,
+		" 5 % ": 0.05,
+	}
+
+	for text, expected := range tests {
+		var p Percent
+		require.NoError(t, p.UnmarshalText([]byte(text)), text)
+		assert.InDelta(t, float64(expected), float64(p), 1e-9, text)
+	}
+
+	var p Percent
+	assert.EqualError(t, p.UnmarshalText([]byte("half")), `invalid percentage "half"`)
+	assert.Equal(t, "75%", Percent(0.75).String())
+}
@@ -23,7 +23,12 @@ package config
 import (
 	"encoding"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
+	"regexp"
+	"sort"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,6 +50,8 @@ const (
 	Slice
 	// Dictionary contains words and their definitions
 	Dictionary
+	// Duration holds time.Duration values, e.g. of !duration tags.
+	Duration
 	// Zero constants
 	_float64Zero = float64(0)
 
@@ -62,6 +69,8 @@ func GetType(value interface{}) ValueType {
 	switch value.(type) {
 	case string:
 		return String
+	case time.Duration:
+		return Duration
 	case int, int32, int64, byte:
 		return Integer
 	case bool:
@@ -91,6 +100,38 @@ type Value struct {
 	defaultValue interface{}
 	Timestamp    time.Time
 	Type         ValueType
+	version      uint64
+
+	// The value has !delete markers for lower priority providers in a group.
+	deletions bool
+}
+
+// _lastVersion is the last version assigned to loaded or changed values.
+var _lastVersion uint64
+
+// A revision records when a value was loaded or changed. Versions are
+// global, so they can be compared between providers.
+type revision struct {
+	time    time.Time
+	version uint64
+}
+
+// newRevision returns a revision for values loaded or changed now.
+func newRevision() revision {
+	return revision{time: time.Now(), version: atomic.AddUint64(&_lastVersion, 1)}
+}
+
+// latest returns the revision with the latest time and the highest version of both.
+func (r revision) latest(other revision) revision {
+	if other.time.After(r.time) {
+		r.time = other.time
+	}
+
+	if other.version > r.version {
+		r.version = other.version
+	}
+
+	return r
 }
 
 // NewValue creates a configuration value from a provider and a set
@@ -103,21 +144,31 @@ func NewValue(
 	t ValueType,
 	timestamp *time.Time,
 ) Value {
-	cv := Value{
+	// Providers that don't track updates return values as if they were just read.
+	r := revision{time: time.Now()}
+	if timestamp != nil {
+		r.time = *timestamp
+	}
+
+	return newValue(provider, key, value, found, t, r)
+}
+
+// newValue creates a value loaded or changed at the revision.
+func newValue(provider Provider, key string, value interface{}, found bool, t ValueType, r revision) Value {
+	return Value{
 		provider:     provider,
 		key:          key,
 		value:        value,
 		defaultValue: nil,
+		Timestamp:    r.time,
 		Type:         t,
 		found:        found,
+		version:      r.version,
 	}
+}
 
-	if timestamp == nil {
-		cv.Timestamp = time.Now()
-	} else {
-		cv.Timestamp = *timestamp
-	}
-	return cv
+func (cv Value) revision() revision {
+	return revision{time: cv.Timestamp, version: cv.version}
 }
 
 // Source returns a configuration provider's name
@@ -128,7 +179,9 @@ func (cv Value) Source() string {
 	return cv.provider.Name()
 }
 
-// LastUpdated returns when the configuration value was last updated
+// LastUpdated returns when the configuration value was last updated,
+// e.g. the modification time of the YAML file that defined it.
+// Values of providers that don't track updates report when they were read.
 func (cv Value) LastUpdated() time.Time {
 	if !cv.HasValue() {
 		return time.Time{} // zero value if never updated?
@@ -136,6 +189,14 @@ func (cv Value) LastUpdated() time.Time {
 	return cv.Timestamp
 }
 
+// Version returns a number that increases every time a provider loads or
+// changes values, so a value may have changed if reading the key again returns
+// a higher version. Versions are comparable between providers and keys, they
+// are zero for values of providers that don't track updates.
+func (cv Value) Version() uint64 {
+	return cv.version
+}
+
 // WithDefault sets the default value that can be overridden
 // by providers with a highger priority.
 func (cv Value) WithDefault(value interface{}) Value {
@@ -144,7 +205,8 @@ func (cv Value) WithDefault(value interface{}) Value {
 	return cv
 }
 
-// ChildKeys returns the child keys
+// ChildKeys returns the child keys, map keys are sorted. Use Keys to get
+// all the nested keys.
 func (cv Value) ChildKeys() []string {
 	var slice []interface{}
 	if err := cv.Populate(&slice); err != nil {
@@ -161,11 +223,13 @@ func (cv Value) ChildKeys() []string {
 		return nil
 	}
 
+	keys := make([]string, 0, len(m))
 	for k := range m {
-		res = append(res, k)
+		keys = append(keys, k)
 	}
 
-	return res
+	sort.Strings(keys)
+	return append(res, keys...)
 }
 
 // String prints out underline value in Value with fmt.Sprint.
@@ -245,13 +309,20 @@ func (cv Value) AsBool() bool {
 	return s
 }
 
-// IsDefault returns whether the return value is the default.
+// IsDefault returns whether the return value is the default, i.e. no provider
+// defined the key and the value was set with WithDefault. A value defined by
+// a provider is not a default, even if it is equal to the default.
 func (cv Value) IsDefault() bool {
-	// TODO(ai) what should the semantics be if the provider has a value that's
-	// the same as the default value?
 	return !cv.found && cv.defaultValue != nil
 }
 
+// IsNull returns whether a provider defined the key with a null value, e.g. "key: ~".
+// Missing keys, deleted keys and defaults are not null. Null values don't override
+// values of lower priority files and providers, use !delete to remove them.
+func (cv Value) IsNull() bool {
+	return cv.found && cv.value == nil
+}
+
 // HasValue returns whether the configuration has a value that can be used
 func (cv Value) HasValue() bool {
 	return cv.found || cv.IsDefault()
@@ -260,6 +331,9 @@ func (cv Value) HasValue() bool {
 // Value returns the underlying configuration's value
 func (cv Value) Value() interface{} {
 	if cv.found {
+		if cv.deletions {
+			return withoutDeletions(cv.value)
+		}
 		return cv.value
 	}
 	return cv.defaultValue
@@ -291,6 +365,28 @@ func convertValue(value interface{}, targetType reflect.Type) (interface{}, erro
 		switch t := target.(type) {
 		case *time.Duration:
 			return time.ParseDuration(v)
+		case *url.URL:
+			u, err := url.Parse(v)
+			if err != nil {
+				return nil, err
+			}
+
+			return *u, nil
+		case *net.IPNet:
+			_, n, err := net.ParseCIDR(v)
+			if err != nil {
+				return nil, err
+			}
+
+			return *n, nil
+		case *regexp.Regexp:
+			// Regexp implements encoding.TextUnmarshaler only since Go 1.21.
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return nil, err
+			}
+
+			return *re, nil
 		case encoding.TextUnmarshaler:
 			err := t.UnmarshalText([]byte(v))
 			// target should have a pointer receiver to be able to change itself based on text
@@ -0,0 +1,223 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// VariableReport lists variables used to interpolate config files.
+type VariableReport struct {
+	// Referenced are names of all the variables that were looked up.
+	Referenced []string
+
+	// Missing are names of referenced variables that were not set,
+	// e.g. because their defaults were used.
+	Missing []string
+}
+
+// variableRecorder remembers variables looked up with a lookup function.
+type variableRecorder struct {
+	lock   sync.Mutex
+	lookUp lookUpFunc
+	found  map[string]bool
+}
+
+func newVariableRecorder(lookUp lookUpFunc) *variableRecorder {
+	return &variableRecorder{lookUp: lookUp, found: make(map[string]bool)}
+}
+
+func (r *variableRecorder) LookUp(name string) (string, bool) {
+	val, ok := r.lookUp(name)
+
+	r.lock.Lock()
+	r.found[name] = ok
+	r.lock.Unlock()
+
+	return val, ok
+}
+
+func (r *variableRecorder) report() VariableReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return newVariableReport(r.found)
+}
+
+// newVariableReport returns a report for a map of variable names to whether they were found.
+func newVariableReport(found map[string]bool) VariableReport {
+	var res VariableReport
+	for name, ok := range found {
+		res.Referenced = append(res.Referenced, name)
+		if !ok {
+			res.Missing = append(res.Missing, name)
+		}
+	}
+
+	sort.Strings(res.Referenced)
+	sort.Strings(res.Missing)
+	return res
+}
+
+// merge returns a union of two reports, variables found in any
+// of the reports are not missing.
+func (r VariableReport) merge(other VariableReport) VariableReport {
+	found := make(map[string]bool)
+	for _, report := range []VariableReport{r, other} {
+		for _, name := range report.Referenced {
+			found[name] = found[name] || !contains(report.Missing, name)
+		}
+	}
+
+	return newVariableReport(found)
+}
+
+// chainLookUp returns a lookup function that returns the first value found.
+func chainLookUp(lookUps ...lookUpFunc) lookUpFunc {
+	return func(name string) (string, bool) {
+		for _, lookUp := range lookUps {
+			if lookUp == nil {
+				continue
+			}
+
+			if val, ok := lookUp(name); ok {
+				return val, true
+			}
+		}
+
+		return "", false
+	}
+}
+
+func mapLookUp(vars map[string]string) lookUpFunc {
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	}
+}
+
+// readEnvFile reads variables from a file with NAME=value lines. Empty lines and
+// lines starting with # are skipped, values can be quoted and lines can start
+// with export, like in shell scripts.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open env file")
+	}
+
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+		eq := strings.IndexByte(text, '=')
+		if eq < 1 {
+			return nil, fmt.Errorf("%s:%d: expected NAME=value", path, line)
+		}
+
+		name, val := strings.TrimSpace(text[:eq]), strings.TrimSpace(text[eq+1:])
+		if len(val) > 1 && val[0] == '"' && val[len(val)-1] == '"' {
+			if val, err = strconv.Unquote(val); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid quoted value for %s: %v", path, line, name, err)
+			}
+		} else if len(val) > 1 && val[0] == '\'' && val[len(val)-1] == '\'' {
+			val = val[1 : len(val)-1]
+		}
+
+		vars[name] = val
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "can't read env file %q", path)
+	}
+
+	return vars, nil
+}
+
+// variableLookUp returns the lookup function for interpolation: variables
+// set with SetVariables are used first, then the loader lookup function
+// and then variables from env files in the order of files.
+func (l *Loader) variableLookUp() (lookUpFunc, error) {
+	l.lock.RLock()
+	lookUps := []lookUpFunc{mapLookUp(l.variables), l.lookUp}
+	envFiles := append([]string(nil), l.envFiles...)
+	l.lock.RUnlock()
+
+	for _, file := range envFiles {
+		vars, err := readEnvFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		lookUps = append(lookUps, mapLookUp(vars))
+	}
+
+	return chainLookUp(lookUps...), nil
+}
+
+// SetVariables sets variables to interpolate config files with, they take
+// precedence over environment variables.
+func (l *Loader) SetVariables(vars map[string]string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.variables = make(map[string]string, len(vars))
+	for k, v := range vars {
+		l.variables[k] = v
+	}
+}
+
+// SetEnvFiles sets files with NAME=value lines to read variables from for
+// interpolation of config files. Environment variables take precedence over
+// values in the files and earlier files take precedence over later ones.
+// The files are read on every Load and Reload.
+func (l *Loader) SetEnvFiles(files ...string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.envFiles = files
+}
+
+// Variables returns variables referenced by config files loaded with the
+// last successful Load or Reload call.
+func (l *Loader) Variables() VariableReport {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return VariableReport{
+		Referenced: append([]string(nil), l.variableReport.Referenced...),
+		Missing:    append([]string(nil), l.variableReport.Missing...),
+	}
+}
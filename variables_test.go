@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestReadEnvFile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, ".env")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+# Comment
+HOST=gotham
+export PORT = 80
+EMPTY=
+QUOTED="a \"b\"\n"
+SINGLE='$HOME'
+`), os.ModePerm))
+
+	vars, err := readEnvFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"HOST":   "gotham",
+		"PORT":   "80",
+		"EMPTY":  "",
+		"QUOTED": "a \"b\"\n",
+		"SINGLE": "$HOME",
+	}, vars)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("HOST=gotham\nPORT"), os.ModePerm))
+	_, err = readEnvFile(file)
+	assert.EqualError(t, err, file+":2: expected NAME=value")
+
+	_, err = readEnvFile(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestVariableReport_Merge(t *testing.T) {
+	t.Parallel()
+
+	r := VariableReport{Referenced: []string{"A", "B"}, Missing: []string{"A", "B"}}
+	assert.Equal(t,
+		VariableReport{Referenced: []string{"A", "B", "C"}, Missing: []string{"A"}},
+		r.merge(VariableReport{Referenced: []string{"B", "C"}}))
+}
+
+func TestLoader_Variables(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		envFile := filepath.Join(dir, "test.env")
+		require.NoError(t, ioutil.WriteFile(envFile, []byte("HOST=file\nPORT=8080\nUSER=file"), os.ModePerm))
+		defer os.Remove(envFile)
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		l.SetEnvFiles(envFile)
+		l.SetVariables(map[string]string{"HOST": "vars"})
+		l.SetLookupFn(func(key string) (string, bool) {
+			if key == "USER" {
+				return "env", true
+			}
+
+			return "", false
+		})
+
+		p := l.Load()
+		assert.Equal(t, "vars", p.Get("host").AsString())
+		assert.Equal(t, 8080, p.Get("port").AsInt())
+		assert.Equal(t, "env", p.Get("user").AsString())
+		assert.Equal(t, "none", p.Get("password").AsString())
+		assert.Equal(t, VariableReport{
+			Referenced: []string{"HOST", "PASSWORD", "PORT", "USER"},
+			Missing:    []string{"PASSWORD"},
+		}, l.Variables())
+
+		require.NoError(t, os.Remove(envFile))
+		assert.Error(t, l.Reload(), "missing env file should fail reload")
+	}
+
+	withBase(t, f, "host: ${HOST}\nport: ${PORT}\nuser: ${USER}\npassword: ${PASSWORD:none}")
+}
@@ -27,38 +27,80 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/go-yaml/yaml"
 	"github.com/pkg/errors"
 )
 
 type yamlConfigProvider struct {
 	root *yamlNode
+
+	// Values are as old as the latest modification of files that define them,
+	// values from readers without modification times are as old as the provider.
+	loaded   revision
+	modTimes map[string]time.Time
+
+	// The tree has !delete markers, they are removed from returned values.
+	deletions bool
+
+	// Keys that differ by case are different keys, they are rejected otherwise.
+	caseSensitive bool
 }
 
-var (
-	_envSeparator = ":"
-	_emptyDefault = `""`
-)
+// A quoted empty string default in ${VAR:""} is expanded to an empty string.
+const _emptyDefault = `""`
 
 func newYAMLProviderCore(files ...io.ReadCloser) *yamlConfigProvider {
+	p, err := newYAMLProviderCoreWithParser(yamlParser{}, files...)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// newYAMLProviderCoreWithParser merges files parsed with the tag handlers of the parser.
+func newYAMLProviderCoreWithParser(parser yamlParser, files ...io.ReadCloser) (*yamlConfigProvider, error) {
 	var root interface{}
+	var origins *originNode
+	modTimes := make(map[string]time.Time)
 	for _, v := range files {
 		if v == nil {
 			continue
 		}
 
-		var curr interface{}
-		if err := unmarshalYAMLValue(v, &curr); err != nil {
-			if file, ok := v.(*os.File); ok {
-				panic(errors.Wrapf(err, "in file: %q", file.Name()))
+		name := readerName(v)
+		if t, ok := modTime(v); ok && name != "" {
+			modTimes[name] = t
+		}
+
+		curr, currOrigins, err := unmarshalYAMLValue(v, parser.withFile(name))
+		if err != nil {
+			if _, ok := err.(positionError); ok {
+				return nil, err
 			}
 
-			panic(err)
+			if name != "" {
+				return nil, errors.Wrapf(err, "in file: %q", name)
+			}
+
+			return nil, err
+		}
+
+		if err := mergeConflict(Root, root, origins, curr, currOrigins); err != nil {
+			return nil, err
 		}
 
+		if !parser.caseSensitive {
+			if err := caseConflict(Root, yamlLayer{root, origins}, yamlLayer{curr, currOrigins}); err != nil {
+				return nil, err
+			}
+		}
+
+		origins = mergeOrigins(origins, root, currOrigins, curr)
 		root = mergeMaps(root, curr)
 	}
 
@@ -67,8 +109,146 @@ func newYAMLProviderCore(files ...io.ReadCloser) *yamlConfigProvider {
 			nodeType: getNodeType(root),
 			key:      Root,
 			value:    root,
+			origin:   origins,
 		},
+		loaded:        newRevision(),
+		modTimes:      modTimes,
+		deletions:     hasDeletions(root),
+		caseSensitive: parser.caseSensitive,
+	}, nil
+}
+
+// yamlLayer is a parsed YAML value with its origins. Layers are merged in
+// order, later ones override earlier ones.
+type yamlLayer struct {
+	value   interface{}
+	origins *originNode
+}
+
+// caseConflict returns an error for the first pair of map keys that differ
+// only by case in the merged layers, because case insensitive lookups can't
+// tell them apart. The error points to the later definition.
+func caseConflict(key string, layers ...yamlLayer) error {
+	var names []string
+	var merging bool
+	seen := make(map[string]string)
+	children := make(map[string][]yamlLayer)
+	for _, l := range layers {
+		if l.value == nil {
+			continue
+		}
+
+		m, ok := l.value.(map[interface{}]interface{})
+		if !ok || !merging {
+			// Everything but a map merged into a map replaces the previous value.
+			names, seen, children = nil, make(map[string]string), make(map[string][]yamlLayer)
+		}
+
+		merging = ok
+		if list, ok := l.value.([]interface{}); ok {
+			for i, item := range list {
+				name := strconv.Itoa(i)
+				names = append(names, name)
+				children[name] = []yamlLayer{{value: item, origins: l.origins.child(name)}}
+			}
+		}
+
+		for _, k := range definitionOrder(m, l.origins) {
+			name := fmt.Sprint(k)
+			child := yamlLayer{value: m[k], origins: l.origins.child(name)}
+			prev, ok := seen[strings.ToLower(name)]
+			if ok && prev != name {
+				return caseConflictError(joinKey(key, name), child.origins, joinKey(key, prev), children[prev])
+			}
+
+			if !ok {
+				seen[strings.ToLower(name)] = name
+				names = append(names, name)
+			}
+
+			children[name] = append(children[name], child)
+		}
+	}
+
+	for _, name := range names {
+		if err := caseConflict(joinKey(key, name), children[name]...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// caseConflictError reports the key defined at origins that clashes with
+// the earlier key defined in layers, both with positions of the keys.
+func caseConflictError(key string, origins *originNode, prev string, layers []yamlLayer) error {
+	msg := fmt.Sprintf("key %q differs only by case from %q", key, prev)
+	if loc := layers[len(layers)-1].origins.keyPosition().location(); loc != "" {
+		msg += " defined at " + loc
+	}
+
+	pos := origins.keyPosition()
+	if pos.File == "" {
+		return errors.New(msg)
+	}
+
+	return positionError{file: pos.File, line: pos.Line, column: pos.Column, err: errors.New(msg)}
+}
+
+// definitionOrder returns keys of the map in the order they are defined in
+// files, keys without positions go first and are sorted by name.
+func definitionOrder(m map[interface{}]interface{}, origins *originNode) []interface{} {
+	keys := sortedKeys(m)
+	sort.SliceStable(keys, func(i, j int) bool {
+		a := origins.child(fmt.Sprint(keys[i])).keyPosition()
+		b := origins.child(fmt.Sprint(keys[j])).keyPosition()
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return keys
+}
+
+// modTime returns the modification time of a file reader.
+func modTime(reader io.Reader) (time.Time, bool) {
+	info := fileInfo(reader)
+	if info == nil {
+		return time.Time{}, false
+	}
+
+	return info.ModTime(), true
+}
+
+// fileInfo returns the file info of a file reader, e.g. *os.File, or nil.
+func fileInfo(reader io.Reader) os.FileInfo {
+	f, ok := reader.(interface {
+		Stat() (os.FileInfo, error)
+	})
+
+	if !ok {
+		return nil
 	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+
+	return info
+}
+
+// readerName returns a file name for readers that have one, e.g. *os.File.
+func readerName(reader io.Reader) string {
+	if named, ok := reader.(interface {
+		Name() string
+	}); ok {
+		return named.Name()
+	}
+
+	return ""
 }
 
 // We need to have a custom merge map because yamlV2 doesn't unmarshal `map[interface{}]map[interface{}]interface{}`
@@ -82,9 +262,10 @@ func newYAMLProviderCore(files ...io.ReadCloser) *yamlConfigProvider {
 //
 // * if A is a map and B is not, this function will panic, e.g. key:value and -slice
 //
-// * in all the remaining cases B will overwrite A.
+// * in all the remaining cases B will overwrite A, except a nil B that keeps A.
+//   A deleted B (!delete) overwrites A too, so the deletion applies to lower priority values.
 func mergeMaps(dst interface{}, src interface{}) interface{} {
-	if dst == nil {
+	if dst == nil || isDeleted(dst) {
 		return src
 	}
 
@@ -118,6 +299,143 @@ func mergeMaps(dst interface{}, src interface{}) interface{} {
 	return dst
 }
 
+// _deleteTag marks keys removed from lower priority files and providers.
+const _deleteTag = "!delete"
+
+// deletion is the value of keys tagged with !delete. Merges keep it to remove
+// the key from lower priority values, so a group can remove values of lower
+// priority providers too. Providers return it as a value that is not found.
+type deletion struct{}
+
+var _deleted = deletion{}
+
+func isDeleted(value interface{}) bool {
+	_, ok := value.(deletion)
+	return ok
+}
+
+// hasDeletions returns true if there are deletion markers in the value tree.
+func hasDeletions(value interface{}) bool {
+	switch v := value.(type) {
+	case deletion:
+		return true
+	case map[interface{}]interface{}:
+		for _, item := range v {
+			if hasDeletions(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// withoutDeletions returns a copy of the value tree without deleted keys.
+func withoutDeletions(value interface{}) interface{} {
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return value
+	}
+
+	res := make(map[interface{}]interface{}, len(m))
+	for k, v := range m {
+		if !isDeleted(v) {
+			res[k] = withoutDeletions(v)
+		}
+	}
+
+	return res
+}
+
+// copyMaps returns a copy of the value with all nested maps copied.
+func copyMaps(value interface{}) interface{} {
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return value
+	}
+
+	res := make(map[interface{}]interface{}, len(m))
+	for k, v := range m {
+		res[k] = copyMaps(v)
+	}
+
+	return res
+}
+
+// mergeConflict returns an error with positions of both definitions if mergeMaps
+// can't merge src into dst, i.e. src is a map and dst is not. Values without
+// files are left to mergeMaps.
+func mergeConflict(key string, dst interface{}, dstOrigins *originNode, src interface{}, srcOrigins *originNode) error {
+	srcMap, ok := src.(map[interface{}]interface{})
+	if !ok || dst == nil || isDeleted(dst) {
+		return nil
+	}
+
+	dstMap, ok := dst.(map[interface{}]interface{})
+	if !ok {
+		pos := srcOrigins.position()
+		if pos.File == "" {
+			return nil
+		}
+
+		return positionError{
+			file:   pos.File,
+			line:   pos.Line,
+			column: pos.Column,
+			err: fmt.Errorf("can't merge a map into the %T value of the key %q defined at %s",
+				dst, key, dstOrigins.position().location()),
+		}
+	}
+
+	for k, v := range srcMap {
+		name := fmt.Sprint(k)
+		if err := mergeConflict(joinKey(key, name), dstMap[k], dstOrigins.child(name), v, srcOrigins.child(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeOrigins merges origins of the src value into origins of the dst value
+// following the same rules as mergeMaps. It has to be called before mergeMaps
+// modifies dst.
+func mergeOrigins(dst *originNode, dstVal interface{}, src *originNode, srcVal interface{}) *originNode {
+	// Definitions before a deletion don't contribute to the value.
+	if dst == nil || isDeleted(dstVal) || isDeleted(srcVal) {
+		return src
+	}
+
+	if src == nil || srcVal == nil && dstVal != nil {
+		return dst
+	}
+
+	res := &originNode{
+		origins:  append(append([]Origin(nil), dst.origins...), src.origins...),
+		children: src.children,
+		plain:    src.plain,
+		key:      src.key,
+	}
+
+	srcMap, ok := srcVal.(map[interface{}]interface{})
+	dstMap, dstIsMap := dstVal.(map[interface{}]interface{})
+	if !ok || !dstIsMap {
+		return res
+	}
+
+	res.children = make(map[string]*originNode, len(dst.children)+len(src.children))
+	for k, v := range dst.children {
+		res.children[k] = v
+	}
+
+	for k, v := range srcMap {
+		key := fmt.Sprint(k)
+		res.children[key] = mergeOrigins(dst.child(key), dstMap[k], src.child(key), v)
+	}
+
+	return res
+}
+
 // NewYAMLProviderFromFiles creates a configuration provider from a set of YAML file names.
 // All the objects are going to be merged and arrays/values overridden in the order of the files.
 func NewYAMLProviderFromFiles(mustExist bool, resolver FileResolver, files ...string) Provider {
@@ -125,7 +443,8 @@ func NewYAMLProviderFromFiles(mustExist bool, resolver FileResolver, files ...st
 }
 
 // NewYAMLProviderWithExpand creates a configuration provider from a set of YAML file names with ${var} or $var values
-// replaced based on the mapping function.
+// replaced based on the mapping function. It panics if a value can't be interpolated,
+// it is kept for compatibility, use NewExpandedYAMLProvider to get an error instead.
 func NewYAMLProviderWithExpand(mustExist bool, resolver FileResolver, mapping func(string) (string, bool), files ...string) Provider {
 	return NewYAMLProviderFromReaderWithExpand(mapping, filesToReaders(mustExist, resolver, files...)...)
 }
@@ -136,12 +455,139 @@ func NewYAMLProviderFromReader(readers ...io.ReadCloser) Provider {
 	return NewCachedProvider(newYAMLProviderCore(readers...))
 }
 
+// NewCaseSensitiveYAMLProviderFromReader creates a configuration provider from
+// a list of `io.ReadClosers` that treats keys differing by case as different keys,
+// e.g. Get("timeout") doesn't return a value for Timeout.
+func NewCaseSensitiveYAMLProviderFromReader(readers ...io.ReadCloser) Provider {
+	p, err := newYAMLProviderCoreWithParser(yamlParser{caseSensitive: true}, readers...)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewCachedProvider(p)
+}
+
 // NewYAMLProviderFromReaderWithExpand creates a configuration provider from a list of `io.ReadClosers`
 // and uses the mapping function to expand values in the underlying provider.
+// It panics if a value can't be interpolated, e.g. a variable without a default is not set,
+// it is kept for compatibility, use NewExpandedYAMLProvider to get an error instead.
 func NewYAMLProviderFromReaderWithExpand(mapping func(string) (string, bool), readers ...io.ReadCloser) Provider {
-	p := newYAMLProviderCore(readers...)
-	p.root.applyOnAllNodes(replace(mapping))
-	return NewCachedProvider(p)
+	p, err := NewExpandedYAMLProvider(mapping, readers...)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// NewExpandedYAMLProvider creates a configuration provider from a list of `io.ReadClosers`
+// and uses the mapping function to expand values in the underlying provider. It returns
+// an error with the key and the position of a value that can't be interpolated.
+func NewExpandedYAMLProvider(mapping func(string) (string, bool), readers ...io.ReadCloser) (Provider, error) {
+	p, err := newYAMLProviderWithExpand(yamlParser{}, expander{lookUp: mapping, retype: true}, readers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCachedProvider(p), nil
+}
+
+func newYAMLProviderWithExpand(parser yamlParser, e expander, readers ...io.ReadCloser) (*yamlConfigProvider, error) {
+	p, err := newYAMLProviderCoreWithParser(parser, readers...)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := e.expand(Root, p.root.value, p.root.origin)
+	if err != nil {
+		return nil, err
+	}
+
+	p.root = &yamlNode{
+		nodeType: getNodeType(value),
+		key:      Root,
+		value:    value,
+		origin:   p.root.origin,
+	}
+
+	return p, nil
+}
+
+// expander interpolates ${...} expressions in values.
+type expander struct {
+	lookUp lookUpFunc
+
+	// A plain scalar with a single expression gets the type of the expanded
+	// value, e.g. port: ${PORT:8080} is an integer.
+	retype bool
+}
+
+// expand returns a copy of the value with ${...} expressions interpolated.
+func (e expander) expand(key string, value interface{}, origins *originNode) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			name := fmt.Sprint(k)
+			expanded, err := e.expand(joinKey(key, name), item, origins.child(name))
+			if err != nil {
+				return nil, err
+			}
+
+			res[k] = expanded
+		}
+
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			name := strconv.Itoa(i)
+			expanded, err := e.expand(joinKey(key, name), item, origins.child(name))
+			if err != nil {
+				return nil, err
+			}
+
+			res[i] = expanded
+		}
+
+		return res, nil
+	}
+
+	// Only strings with expressions are rewritten, other values keep their types.
+	// Values of tagged scalars are resolved by their tag handlers, e.g. a !secret
+	// value or the contents of a !file can have a literal $ in them.
+	s, ok := value.(string)
+	if !ok || !strings.Contains(s, "$") || origins.position().Tag != "" {
+		return value, nil
+	}
+
+	in, err := parseInterpolation(s)
+	if err == nil {
+		s, err = in.expand(e.lookUp)
+	}
+
+	if err != nil {
+		return nil, interpolationError(key, origins, err)
+	}
+
+	if e.retype && len(in) == 1 && in[0].variable != nil && origins != nil && origins.plain && s != "" {
+		return resolvePlainScalar(s), nil
+	}
+
+	return s, nil
+}
+
+// interpolationError returns an error with the key and the position of a value
+// that failed to interpolate.
+func interpolationError(key string, origins *originNode, err error) error {
+	err = errors.Wrapf(err, "can't interpolate the value for the key %q", key)
+	if pos := origins.position(); pos.File != "" {
+		return positionError{file: pos.File, line: pos.Line, column: pos.Column, err: err}
+	}
+
+	return err
 }
 
 // NewYAMLProviderFromBytes creates a config provider from a byte-backed YAML blobs.
@@ -179,7 +625,7 @@ func (y yamlConfigProvider) getNode(key string) *yamlNode {
 		return y.root
 	}
 
-	return y.root.Find(key)
+	return y.root.find(ParsePath(key), strings.Contains(key, _escape), y.caseSensitive)
 }
 
 // Name returns the config provider name
@@ -191,10 +637,86 @@ func (y yamlConfigProvider) Name() string {
 func (y yamlConfigProvider) Get(key string) Value {
 	node := y.getNode(key)
 	if node == nil {
-		return NewValue(y, key, nil, false, Invalid, nil)
+		if y.deletions && y.parentDeleted(key) {
+			return newValue(y, key, _deleted, false, Invalid, y.loaded)
+		}
+
+		return newValue(y, key, nil, false, Invalid, y.loaded)
+	}
+
+	if isDeleted(node.value) {
+		// The marker is kept to remove the key from lower priority providers in a group.
+		return newValue(y, key, node.value, false, Invalid, y.revision(node.origin))
+	}
+
+	v := newValue(y, key, node.value, true, GetType(node.value), y.revision(node.origin))
+	v.deletions = y.deletions
+	return v
+}
+
+// parentDeleted returns true if the closest defined parent of the key is deleted,
+// so the key is deleted in lower priority providers of a group too.
+func (y yamlConfigProvider) parentDeleted(key string) bool {
+	path := ParsePath(key)
+	for i := len(path) - 1; i > 0; i-- {
+		if node := y.root.find(path[:i], strings.Contains(key, _escape), y.caseSensitive); node != nil {
+			return isDeleted(node.value)
+		}
+	}
+
+	return false
+}
+
+// revision returns the latest modification time of files that define values in the tree.
+func (y yamlConfigProvider) revision(origins *originNode) revision {
+	if origins == nil {
+		return y.loaded
+	}
+
+	res := revision{version: y.loaded.version}
+	for _, o := range origins.origins {
+		t, ok := y.modTimes[o.File]
+		if !ok {
+			t = y.loaded.time
+		}
+
+		res = res.latest(revision{time: t})
+	}
+
+	for _, child := range origins.children {
+		res = res.latest(y.revision(child))
 	}
 
-	return NewValue(y, key, node.value, true, GetType(node.value), nil)
+	if res.time.IsZero() {
+		res.time = y.loaded.time
+	}
+
+	return res
+}
+
+// Keys returns keys of all scalar values under the key.
+func (y yamlConfigProvider) Keys(key string) []string {
+	v := y.Get(key)
+	if !v.HasValue() {
+		return nil
+	}
+
+	// treeKeys skips deleted keys, but keeps maps with deleted keys only.
+	return treeKeys(key, v.value)
+}
+
+// Origins returns file positions of all definitions of the value.
+func (y yamlConfigProvider) Origins(key string) []Origin {
+	node := y.getNode(key)
+	if node == nil || isDeleted(node.value) {
+		return nil
+	}
+
+	if node.origin == nil {
+		return []Origin{{Provider: y.Name(), Value: node.value}}
+	}
+
+	return renameOrigins(append([]Origin(nil), node.origin.origins...), y.Name())
 }
 
 func (y yamlConfigProvider) RegisterChangeCallback(key string, callback ChangeCallback) error {
@@ -222,6 +744,7 @@ type yamlNode struct {
 	key      string
 	value    interface{}
 	children []*yamlNode
+	origin   *originNode
 }
 
 func (n yamlNode) Key() string {
@@ -237,25 +760,31 @@ func (n yamlNode) Type() reflect.Type {
 }
 
 // Find the first longest match in child nodes for the dottedPath.
+// Paths with escaped separators, e.g. `hosts.example\.com`, are matched
+// segment by segment instead.
 func (n *yamlNode) Find(dottedPath string) *yamlNode {
-	for curr := dottedPath; len(curr) != 0; {
+	return n.find(ParsePath(dottedPath), strings.Contains(dottedPath, _escape), false)
+}
+
+func (n *yamlNode) find(path Path, exact, caseSensitive bool) *yamlNode {
+	longest := len(path)
+	if exact {
+		longest = 1
+	}
+
+	for i := longest; i > 0; i-- {
+		curr := strings.Join(path[:i], _separator)
 		for _, v := range n.Children() {
-			if strings.EqualFold(v.key, curr) {
-				if curr == dottedPath {
+			if v.key == curr || !caseSensitive && strings.EqualFold(v.key, curr) {
+				if i == len(path) {
 					return v
 				}
 
-				if node := v.Find(dottedPath[len(curr)+1:]); node != nil {
+				if node := v.find(path[i:], exact, caseSensitive); node != nil {
 					return node
 				}
 			}
 		}
-
-		if last := strings.LastIndex(curr, _separator); last > 0 {
-			curr = curr[:last]
-		} else {
-			break
-		}
 	}
 
 	return nil
@@ -268,21 +797,25 @@ func (n *yamlNode) Children() []*yamlNode {
 		switch n.nodeType {
 		case objectNode:
 			for k, v := range n.value.(map[interface{}]interface{}) {
+				// We need to use a default format, because key may be not a string.
+				key := fmt.Sprintf("%v", k)
 				n2 := &yamlNode{
 					nodeType: getNodeType(v),
-					// We need to use a default format, because key may be not a string.
-					key:   fmt.Sprintf("%v", k),
-					value: v,
+					key:      key,
+					value:    v,
+					origin:   n.origin.child(key),
 				}
 
 				n.children = append(n.children, n2)
 			}
 		case arrayNode:
 			for k, v := range n.value.([]interface{}) {
+				key := strconv.Itoa(k)
 				n2 := &yamlNode{
 					nodeType: getNodeType(v),
-					key:      strconv.Itoa(k),
+					key:      key,
 					value:    v,
+					origin:   n.origin.child(key),
 				}
 
 				n.children = append(n.children, n2)
@@ -295,72 +828,18 @@ func (n *yamlNode) Children() []*yamlNode {
 	return nodes
 }
 
-func (n *yamlNode) applyOnAllNodes(expand func(string) string) {
-	if n == nil {
-		return
-	}
-
-	if n.nodeType == valueNode && n.value != nil {
-		n.value = os.Expand(fmt.Sprint(n.value), expand)
-	}
-
-	for _, c := range n.Children() {
-		c.applyOnAllNodes(expand)
-	}
-}
-
-func unmarshalYAMLValue(reader io.ReadCloser, value interface{}) error {
+func unmarshalYAMLValue(reader io.ReadCloser, parser yamlParser) (interface{}, *originNode, error) {
 	raw, err := ioutil.ReadAll(reader)
 	if err != nil {
-		return errors.Wrap(err, "failed to read the yaml config")
+		return nil, nil, errors.Wrap(err, "failed to read the yaml config")
 	}
 
-	if err = yaml.Unmarshal(raw, value); err != nil {
-		return err
+	value, origins, err := parser.parse(raw)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return reader.Close()
-}
-
-// Function to expand environment variables in returned values that have form: ${ENV_VAR:DEFAULT_VALUE}.
-// For example, if an HTTP_PORT environment variable should be used for the HTTP module
-// port, the config would look like this:
-//
-//   modules:
-//     http:
-//       port: ${HTTP_PORT:8080}
-//
-// In the case that HTTP_PORT is not provided, default value (in this case 8080)
-// will be used
-//
-// TODO: what if someone wanted a literal ${FOO} in config? need a small escape hatch
-func replace(lookUp lookUpFunc) func(in string) string {
-	return func(in string) string {
-		sep := strings.Index(in, _envSeparator)
-		var key string
-		var def string
-
-		if sep == -1 {
-			// separator missing - everything is the key ${KEY}
-			key = in
-		} else {
-			// ${KEY:DEFAULT}
-			key = in[:sep]
-			def = in[sep+1:]
-		}
-
-		if envVal, ok := lookUp(key); ok {
-			return envVal
-		}
-
-		if def == "" {
-			panic(fmt.Sprintf(`default is empty for %q (use "" for empty string)`, key))
-		} else if def == _emptyDefault {
-			return ""
-		}
-
-		return def
-	}
+	return value, origins, reader.Close()
 }
 
 func getNodeType(val interface{}) nodeType {
@@ -0,0 +1,435 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-yaml/yaml"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// originNode mirrors a parsed YAML tree and keeps all the definitions of its value
+// in the order they were merged, the last one is the definition in use.
+type originNode struct {
+	origins  []Origin
+	children map[string]*originNode
+
+	// Plain scalars are not quoted, tagged or block scalars.
+	plain bool
+
+	// Position of the map key of the definition in use, if the value is in a map.
+	key Origin
+}
+
+func (n *originNode) child(key string) *originNode {
+	if n == nil {
+		return nil
+	}
+
+	return n.children[key]
+}
+
+// position returns the definition of the value in use.
+func (n *originNode) position() Origin {
+	if n == nil || len(n.origins) == 0 {
+		return Origin{}
+	}
+
+	return n.origins[len(n.origins)-1]
+}
+
+// keyPosition returns the position of the map key of the definition in use.
+func (n *originNode) keyPosition() Origin {
+	if n == nil {
+		return Origin{}
+	}
+
+	return n.key
+}
+
+// yamlParser converts YAML documents to the same trees of map[interface{}]interface{},
+// []interface{} and scalars as yaml.Unmarshal does, but it also records
+// file positions of every value.
+type yamlParser struct {
+	file string
+
+	// Handlers for application specific tags. Parsers outside of the Loader
+	// only resolve tags that don't read files or variables if it is nil.
+	tags map[string]TagHandler
+
+	// Variables for tag handlers, there are no variables if it is nil.
+	lookUp lookUpFunc
+
+	// Resolver for files read by tag handlers, e.g. !file.
+	resolver FileResolver
+
+	// Environment for documents with a when selector.
+	environment string
+
+	// Keys that differ only by case are allowed and looked up separately.
+	caseSensitive bool
+}
+
+// _whenKey is a key of a document selector, e.g. when: {environment: production}.
+const _whenKey = "when"
+
+// withFile returns a copy of the parser for the file.
+func (p yamlParser) withFile(file string) yamlParser {
+	p.file = file
+	return p
+}
+
+// parse merges all documents of a YAML stream in order, the same way files are merged.
+func (p yamlParser) parse(raw []byte) (interface{}, *originNode, error) {
+	var root interface{}
+	var origins *originNode
+
+	dec := yaml3.NewDecoder(bytes.NewReader(raw))
+	for {
+		var doc yaml3.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			return root, origins, nil
+		} else if err != nil {
+			return nil, nil, p.syntaxError(err)
+		}
+
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		val, o, err := p.node(doc.Content[0])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isDeleted(val) {
+			return nil, nil, p.errorf(doc.Content[0], "%s can only be used for map values", _deleteTag)
+		}
+
+		selected, err := p.selected(doc.Content[0], val, o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if selected {
+			if err := mergeConflict(Root, root, origins, val, o); err != nil {
+				return nil, nil, err
+			}
+
+			origins = mergeOrigins(origins, root, o, val)
+			root = mergeMaps(root, val)
+		}
+	}
+}
+
+// selected removes the when selector from a document and returns true if the document
+// applies to the environment of the parser. Documents without a selector always apply.
+// Parsers without an environment, e.g. outside of the Loader, treat when as a regular key.
+func (p yamlParser) selected(n *yaml3.Node, val interface{}, origin *originNode) (bool, error) {
+	m, ok := val.(map[interface{}]interface{})
+	if !ok || p.environment == "" {
+		return true, nil
+	}
+
+	when, ok := m[_whenKey]
+	if !ok {
+		return true, nil
+	}
+
+	delete(m, _whenKey)
+	delete(origin.children, _whenKey)
+
+	selector, ok := when.(map[interface{}]interface{})
+	if !ok {
+		return false, p.errorf(n, "%s must be a map, e.g. %s: {environment: production}", _whenKey, _whenKey)
+	}
+
+	for k, v := range selector {
+		if k != "environment" {
+			return false, p.errorf(n, "unknown %s selector %q", _whenKey, fmt.Sprint(k))
+		}
+
+		envs, ok := v.([]interface{})
+		if !ok {
+			envs = []interface{}{v}
+		}
+
+		for _, env := range envs {
+			if fmt.Sprint(env) == p.environment {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (p yamlParser) node(n *yaml3.Node) (interface{}, *originNode, error) {
+	origin := &originNode{
+		origins: []Origin{{File: p.file, Line: n.Line, Column: n.Column}},
+	}
+
+	if n.Kind == yaml3.AliasNode {
+		return p.node(n.Alias)
+	}
+
+	if tag := n.ShortTag(); isApplicationTag(tag) {
+		if n.Kind != yaml3.ScalarNode {
+			return nil, nil, p.errorf(n, "tag %q can't be used with collections", tag)
+		}
+
+		origin.origins[0].Tag = tag
+	}
+
+	switch n.Kind {
+	case yaml3.SequenceNode:
+		return p.sequence(n, origin)
+	case yaml3.MappingNode:
+		return p.mapping(n, origin)
+	}
+
+	val, err := p.scalar(n)
+	if err != nil {
+		return nil, nil, p.errorf(n, "%v", err)
+	}
+
+	origin.origins[0].Value = val
+	if origin.origins[0].Tag != "" {
+		// Keep the text of tagged values, e.g. the name of a !secret variable.
+		origin.origins[0].Value = n.Value
+	}
+
+	origin.plain = n.Style&(yaml3.TaggedStyle|yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle|
+		yaml3.LiteralStyle|yaml3.FoldedStyle) == 0
+	return val, origin, nil
+}
+
+// positionError is an error with the position of a node that caused it.
+type positionError struct {
+	file         string
+	line, column int
+	err          error
+}
+
+func (e positionError) Error() string {
+	pos := strconv.Itoa(e.line)
+	if e.column > 0 {
+		pos += ":" + strconv.Itoa(e.column)
+	}
+
+	if e.file != "" {
+		pos = e.file + ":" + pos
+	}
+
+	return pos + ": " + e.err.Error()
+}
+
+// _syntaxError matches errors of the yaml package, e.g. "yaml: line 3: did not find expected key".
+var _syntaxError = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// syntaxError adds the file name to a syntax error of the yaml package.
+func (p yamlParser) syntaxError(err error) error {
+	m := _syntaxError.FindStringSubmatch(err.Error())
+	if m == nil || p.file == "" {
+		return err
+	}
+
+	line, _ := strconv.Atoi(m[1])
+	return positionError{file: p.file, line: line, err: errors.New(m[2])}
+}
+
+// errorf returns an error that starts with the position of the node.
+func (p yamlParser) errorf(n *yaml3.Node, format string, args ...interface{}) error {
+	return positionError{file: p.file, line: n.Line, column: n.Column, err: fmt.Errorf(format, args...)}
+}
+
+// isApplicationTag returns true for local tags other than the non-specific "!",
+// e.g. "!env". Standard tags, e.g. "!!str", are handled by the parser.
+func isApplicationTag(tag string) bool {
+	return len(tag) > 1 && tag[0] == '!' && tag[1] != '!'
+}
+
+func (p yamlParser) sequence(n *yaml3.Node, origin *originNode) (interface{}, *originNode, error) {
+	res := make([]interface{}, 0, len(n.Content))
+	origin.children = make(map[string]*originNode, len(n.Content))
+	for i, c := range n.Content {
+		val, o, err := p.node(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isDeleted(val) {
+			return nil, nil, p.errorf(c, "%s can only be used for map values", _deleteTag)
+		}
+
+		res = append(res, val)
+		origin.children[strconv.Itoa(i)] = o
+	}
+
+	origin.origins[0].Value = res
+	return res, origin, nil
+}
+
+func (p yamlParser) mapping(n *yaml3.Node, origin *originNode) (interface{}, *originNode, error) {
+	res := make(map[interface{}]interface{}, len(n.Content)/2)
+	origin.children = make(map[string]*originNode, len(n.Content)/2)
+
+	var merges []*yaml3.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; k.Kind == yaml3.ScalarNode && k.Tag == "!!merge" {
+			merges = append(merges, n.Content[i+1])
+			continue
+		}
+
+		key, _, err := p.node(n.Content[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if key != nil {
+			if kind := reflect.TypeOf(key).Kind(); kind == reflect.Map || kind == reflect.Slice {
+				return nil, nil, p.errorf(n.Content[i], "invalid map key: %v", key)
+			}
+		}
+
+		val, o, err := p.node(n.Content[i+1])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		k := n.Content[i]
+		o.key = Origin{File: p.file, Line: k.Line, Column: k.Column}
+		res[key] = val
+		origin.children[fmt.Sprint(key)] = o
+	}
+
+	// Explicit keys override merged ones, and earlier merged maps override later ones.
+	for _, m := range merges {
+		sources := []*yaml3.Node{m}
+		if m.Kind == yaml3.SequenceNode {
+			sources = m.Content
+		}
+
+		for _, s := range sources {
+			val, o, err := p.node(s)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			merged, ok := val.(map[interface{}]interface{})
+			if !ok {
+				return nil, nil, p.errorf(s, "map merge requires map or sequence of maps as the value")
+			}
+
+			for k, v := range merged {
+				if _, ok := res[k]; !ok {
+					res[k] = v
+					origin.children[fmt.Sprint(k)] = o.child(fmt.Sprint(k))
+				}
+			}
+		}
+	}
+
+	return res, origin, nil
+}
+
+func (p yamlParser) scalar(n *yaml3.Node) (interface{}, error) {
+	if n.Style&yaml3.TaggedStyle == 0 {
+		if n.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle|yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+			return n.Value, nil
+		}
+
+		return resolvePlainScalar(n.Value), nil
+	}
+
+	tag := n.ShortTag()
+	switch {
+	case tag == _deleteTag:
+		if n.Value != "" {
+			return nil, fmt.Errorf("%s doesn't take a value, e.g. key: %s", _deleteTag, _deleteTag)
+		}
+
+		return _deleted, nil
+	case tag == "!!str" || tag == "!":
+		return n.Value, nil
+	case len(tag) > 2 && tag[:2] == "!!":
+		// Let the yaml package resolve the standard tags, e.g. !!int or !!binary.
+		var res interface{}
+		err := yaml.Unmarshal([]byte(tag+" "+strconv.Quote(n.Value)), &res)
+		return res, err
+	}
+
+	if isApplicationTag(tag) {
+		return p.resolveTag(tag, n.Value)
+	}
+
+	return nil, fmt.Errorf("unknown tag %q", tag)
+}
+
+// resolvePlainScalar resolves an unquoted YAML scalar the same way yaml.Unmarshal does,
+// e.g. "yes" is a boolean and "0x10" is an integer.
+func resolvePlainScalar(in string) interface{} {
+	if in == "" {
+		return nil
+	}
+
+	// Plain scalars starting with other letters are always strings.
+	if r, _ := utf8.DecodeRuneInString(in); unicode.IsLetter(r) && !isYAMLWordStart(r) {
+		return in
+	}
+
+	var res interface{}
+	if err := yaml.Unmarshal([]byte(in), &res); err != nil {
+		return in
+	}
+
+	switch res.(type) {
+	case nil:
+		switch in {
+		case "~", "null", "Null", "NULL":
+			return nil
+		}
+
+		return in
+	case map[interface{}]interface{}, []interface{}:
+		return in
+	}
+
+	return res
+}
+
+func isYAMLWordStart(r rune) bool {
+	switch r {
+	case 'y', 'Y', 'n', 'N', 't', 'T', 'f', 'F', 'o', 'O':
+		return true
+	}
+
+	return false
+}
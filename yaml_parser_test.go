@@ -0,0 +1,241 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-yaml/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLParser_SameAsUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]string{
+		"scalars": `
+string: hello
+quoted: "yes"
+single: '1'
+bool: yes
+off: off
+int: 42
+hex: 0x10
+negative: -7
+float: 1.5
+exp: 1e3
+inf: .inf
+null: ~
+empty:
+tilde: ~~
+date: 2017-06-06
+colon: a:b
+literal: |
+  multi
+  line
+`,
+		"tags": `
+str: !!str 12
+int: !!int "12"
+float: !!float "1.5"
+`,
+		"keys": `
+1: one
+true: yes
+1.5: float
+`,
+		"collections": `
+list: [1, two, "3"]
+nested:
+  - a: b
+  - [c, d]
+flow: {a: 1, b: [x]}
+`,
+		"anchors": `
+base: &base
+  name: base
+  port: 80
+other:
+  <<: *base
+  port: 8080
+list: &list [1, 2]
+copy: *list
+`,
+		"multiple merges": `
+a: &a {x: 1, y: 1}
+b: &b {y: 2, z: 2}
+c:
+  <<: [*a, *b]
+  z: 3
+`,
+	}
+
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			var expected interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &expected))
+
+			actual, _, err := yamlParser{}.parse([]byte(doc))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestYAMLParser_Origins(t *testing.T) {
+	t.Parallel()
+
+	val, origins, err := yamlParser{file: "base.yaml"}.parse([]byte(`
+base: &base
+  name: base
+list:
+  - one
+  - two
+other:
+  <<: *base
+`))
+
+	require.NoError(t, err)
+	require.NotNil(t, val)
+
+	assert.Equal(t, []Origin{{File: "base.yaml", Line: 3, Column: 9, Value: "base"}},
+		origins.child("base").child("name").origins)
+	assert.Equal(t, []Origin{{File: "base.yaml", Line: 6, Column: 5, Value: "two"}},
+		origins.child("list").child("1").origins)
+
+	// Merged values point to their definitions.
+	assert.Equal(t, 3, origins.child("other").child("name").origins[0].Line)
+	assert.Nil(t, origins.child("missing").child("key"))
+}
+
+func TestYAMLParser_Errors(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]string{
+		"merge scalar": "a: 1\nb:\n  <<: *x",
+		"bad merge":    "a: &x 1\nb:\n  <<: *x",
+		"map key":      "? [a, b]\n: c",
+		"syntax":       "\t",
+	}
+
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := yamlParser{}.parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestYAMLParser_EmptyDocument(t *testing.T) {
+	t.Parallel()
+
+	val, origins, err := yamlParser{}.parse(nil)
+	require.NoError(t, err)
+	assert.Nil(t, val)
+	assert.Nil(t, origins)
+}
+
+func TestYAMLParser_MultipleDocuments(t *testing.T) {
+	t.Parallel()
+
+	doc := `
+name: base
+db: {host: localhost, port: 5432}
+---
+db: {host: db.internal}
+---
+when: {environment: production}
+name: production
+---
+when: {environment: [development, test]}
+name: development
+`
+
+	val, origins, err := yamlParser{file: "base.yaml", environment: "production"}.parse([]byte(doc))
+	require.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		"name": "production",
+		"db":   map[interface{}]interface{}{"host": "db.internal", "port": 5432},
+	}, val)
+
+	assert.Equal(t, 8, origins.child("name").origins[1].Line)
+	assert.Nil(t, origins.child(_whenKey))
+
+	val, _, err = yamlParser{environment: "test"}.parse([]byte(doc))
+	require.NoError(t, err)
+	assert.Equal(t, "development", val.(map[interface{}]interface{})["name"])
+
+	val, _, err = yamlParser{environment: "staging"}.parse([]byte(doc))
+	require.NoError(t, err)
+	assert.Equal(t, "base", val.(map[interface{}]interface{})["name"])
+}
+
+func TestYAMLParser_WhenWithoutEnvironment(t *testing.T) {
+	t.Parallel()
+
+	val, _, err := yamlParser{}.parse([]byte("job: {name: x}\nwhen: daily"))
+	require.NoError(t, err)
+	assert.Equal(t, "daily", val.(map[interface{}]interface{})[_whenKey])
+
+	p := NewYAMLProviderFromBytes([]byte("a: 1\n---\nwhen: {environment: production}\na: 2"))
+	assert.Equal(t, 2, p.Get("a").Value())
+	assert.Equal(t, "production", p.Get("when.environment").Value())
+}
+
+func TestYAMLParser_SelectorErrors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := yamlParser{file: "base.yaml", environment: "production"}.parse([]byte("a: 1\n---\nwhen: production\na: 2"))
+	assert.EqualError(t, err, "base.yaml:3:1: when must be a map, e.g. when: {environment: production}")
+
+	_, _, err = yamlParser{file: "base.yaml", environment: "production"}.parse([]byte("when: {host: batcave}\na: 2"))
+	assert.EqualError(t, err, `base.yaml:1:1: unknown when selector "host"`)
+}
+
+func TestYAMLParser_ErrorPositions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a: 1\n\tb: 2":           "base.yaml:2: found a tab character that violates indentation",
+		"a:\n  ? [a, b]\n  : c":  "base.yaml:2:5: invalid map key: [a b]",
+		"a: &x 1\nb:\n  <<: *x":  "base.yaml:3:7: map merge requires map or sequence of maps as the value",
+		"a: [1]\n---\na: {b: 1}": `base.yaml:3:4: can't merge a map into the []interface {} value of the key "a" defined at base.yaml:1:4`,
+	}
+
+	for doc, msg := range tests {
+		_, _, err := yamlParser{file: "base.yaml"}.parse([]byte(doc))
+		assert.EqualError(t, err, msg, doc)
+	}
+}
+
+func TestYAMLProvider_MergeConflictPosition(t *testing.T) {
+	t.Parallel()
+
+	_, err := newYAMLProviderCoreWithParser(yamlParser{},
+		namedReader{Reader: strings.NewReader("db:\n  - a"), name: "base.yaml"},
+		namedReader{Reader: strings.NewReader("db:\n  host: b"), name: "production.yaml"},
+	)
+
+	assert.EqualError(t, err,
+		`production.yaml:2:3: can't merge a map into the []interface {} value of the key "db" defined at base.yaml:2:3`)
+}
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -102,10 +103,9 @@ func TestYAMLEnvInterpolationIncomplete(t *testing.T) {
 name: some name here
 telephone: ${SUPPORT_TEL:}`)
 
-	require.Panics(t, func() {
-		f := func(string) (string, bool) { return "", false }
-		NewYAMLProviderFromReaderWithExpand(f, ioutil.NopCloser(cfg))
-	})
+	f := func(string) (string, bool) { return "", false }
+	p := NewYAMLProviderFromReaderWithExpand(f, ioutil.NopCloser(cfg))
+	assert.Equal(t, "", p.Get("telephone").AsString())
 }
 
 func TestYAMLEnvInterpolationWithColon(t *testing.T) {
@@ -209,9 +209,9 @@ func TestNewYAMLProviderFromReader(t *testing.T) {
 func TestYAMLNode(t *testing.T) {
 	t.Parallel()
 	buff := bytes.NewBuffer([]byte("a: b"))
-	node := &yamlNode{value: make(map[interface{}]interface{})}
-	err := unmarshalYAMLValue(ioutil.NopCloser(buff), &node.value)
+	value, _, err := unmarshalYAMLValue(ioutil.NopCloser(buff), yamlParser{})
 	require.NoError(t, err)
+	node := &yamlNode{value: value}
 	assert.Equal(t, "map[a:b]", node.String())
 	assert.Equal(t, "map[interface {}]interface {}", node.Type().String())
 }
@@ -221,7 +221,7 @@ func TestYamlNodeWithNil(t *testing.T) {
 	provider := NewYAMLProviderFromFiles(false, nil)
 	assert.NotNil(t, provider)
 	assert.Panics(t, func() {
-		_ = unmarshalYAMLValue(nil, nil)
+		_, _, _ = unmarshalYAMLValue(nil, yamlParser{})
 	}, "Expected panic with nil inpout.")
 }
 
@@ -1056,3 +1056,195 @@ func TestYAMLEnvInterpolationValueConversion(t *testing.T) {
 	assert.Equal(t, 3, v)
 
 }
+
+func TestYAMLProvider_CaseConflicts(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewYAMLProviderFromBytes([]byte("a:\n  Timeout: 1s\n  timeout: 2s"))
+	})
+
+	_, err := newYAMLProviderCoreWithParser(yamlParser{},
+		namedReader{Reader: strings.NewReader("http:\n  timeout: 1s"), name: "base.yaml"},
+		namedReader{Reader: strings.NewReader("http:\n  Timeout: 2s"), name: "production.yaml"},
+	)
+
+	assert.EqualError(t, err,
+		`production.yaml:2:3: key "http.Timeout" differs only by case from "http.timeout" defined at base.yaml:2:3`)
+
+	_, err = newYAMLProviderCoreWithParser(yamlParser{},
+		namedReader{Reader: strings.NewReader("a: 1\nb: 2\nA: 3"), name: "base.yaml"})
+
+	assert.EqualError(t, err, `base.yaml:3:1: key "A" differs only by case from "a" defined at base.yaml:1:1`)
+
+	_, err = newYAMLProviderCoreWithParser(yamlParser{},
+		namedReader{Reader: strings.NewReader("HTTP: {a: 1}\nhttp: {b: 2}"), name: "base.yaml"})
+
+	assert.EqualError(t, err, `base.yaml:2:1: key "http" differs only by case from "HTTP" defined at base.yaml:1:1`)
+
+	p := NewYAMLProviderFromBytes([]byte("HTTP:\n  Timeout: 1s\nlist: [{A: 1}]"))
+	assert.Equal(t, "1s", p.Get("http.timeout").Value())
+	assert.Equal(t, 1, p.Get("LIST.0.a").Value())
+}
+
+func TestYAMLProvider_CaseSensitive(t *testing.T) {
+	t.Parallel()
+
+	p := NewCaseSensitiveYAMLProviderFromReader(
+		ioutil.NopCloser(strings.NewReader("Timeout: 1s\ntimeout: 2s\nhttp: {Port: 80}")))
+
+	assert.Equal(t, "1s", p.Get("Timeout").Value())
+	assert.Equal(t, "2s", p.Get("timeout").Value())
+	assert.Equal(t, 80, p.Get("http.Port").Value())
+	assert.False(t, p.Get("http.port").HasValue())
+	assert.False(t, p.Get("TIMEOUT").HasValue())
+}
+
+func TestLoader_CaseSensitive(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		l := NewLoader()
+		l.SetDirs(dir)
+		assert.Panics(t, func() { l.Load() })
+
+		l.SetCaseSensitive(true)
+		p := l.Load()
+		assert.Equal(t, "bruce", p.Get("Name").Value())
+		assert.Equal(t, "batman", p.Get("name").Value())
+	}
+
+	withBase(t, f, "Name: bruce\nname: batman")
+}
+
+func TestLoader_SecretsCaseConflict(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader()
+	l.SetFileResolver(mapResolver{
+		"base.yaml":    "db:\n  password: hunter2",
+		"secrets.yaml": "db:\n  Password: swordfish",
+	})
+
+	_, err := l.YamlProvider()()
+	assert.EqualError(t, err,
+		`secrets.yaml:2:3: key "db.Password" differs only by case from "db.password" defined at base.yaml:2:3`)
+}
+
+func TestYAMLProvider_LastUpdated(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestYAMLProvider_LastUpdated")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	base, override := filepath.Join(dir, "base.yaml"), filepath.Join(dir, "override.yaml")
+	require.NoError(t, ioutil.WriteFile(base, []byte("db: {host: localhost, port: 5432}"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(override, []byte("db: {port: 5433}"), os.ModePerm))
+
+	old, recent := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(base, old, old))
+	require.NoError(t, os.Chtimes(override, recent, recent))
+
+	p := NewYAMLProviderFromFiles(true, nil, base, override)
+	assert.True(t, old.Equal(p.Get("db.host").LastUpdated()))
+	assert.True(t, recent.Equal(p.Get("db.port").LastUpdated()))
+	assert.True(t, recent.Equal(p.Get("db").LastUpdated()), "maps are as new as their latest value")
+
+	version := p.Get("db").Version()
+	assert.NotZero(t, version)
+	assert.Equal(t, version, p.Get("db.host").Version())
+
+	reloaded := NewYAMLProviderFromFiles(true, nil, base, override)
+	assert.True(t, reloaded.Get("db").Version() > version)
+
+	start := time.Now()
+	b := NewYAMLProviderFromBytes([]byte("a: 1"))
+	assert.False(t, b.Get("a").LastUpdated().Before(start), "values from bytes are as old as the provider")
+	assert.Equal(t, b.Get("a").LastUpdated(), b.Get("a").LastUpdated())
+}
+
+func TestLoader_LastUpdated(t *testing.T) {
+	t.Parallel()
+
+	f := func(dir string) {
+		old := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, _baseFile), old, old))
+
+		l := NewLoader()
+		l.SetDirs(dir)
+		assert.True(t, old.Equal(l.Load().Get("a").LastUpdated()), "values should be as old as the file")
+	}
+
+	withBase(t, f, "a: 1")
+}
+
+func TestYAMLProvider_Delete(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes(
+		[]byte("db:\n  host: localhost\n  port: 5432\n  pool: {size: 10}\nlogging: {level: info}"),
+		[]byte("db:\n  host: !delete\n  pool: !delete\nlogging: !delete"),
+	)
+
+	assert.False(t, p.Get("db.host").HasValue())
+	assert.False(t, p.Get("db.pool.size").HasValue())
+	assert.False(t, p.Get("logging").HasValue())
+	assert.Equal(t, map[interface{}]interface{}{"port": 5432}, p.Get("db").Value())
+	assert.Equal(t, []string{"db.port"}, p.Get(Root).Keys())
+	assert.Empty(t, originsOf(p, "db.host"))
+
+	var db struct {
+		Host string `default:"db.local"`
+		Port int
+	}
+
+	require.NoError(t, p.Get("db").Populate(&db))
+	assert.Equal(t, "db.local", db.Host)
+	assert.Equal(t, 5432, db.Port)
+
+	readded := NewYAMLProviderFromBytes(
+		[]byte("a: {b: 1, c: 2}"),
+		[]byte("a: !delete"),
+		[]byte("a: {c: 3}"),
+	)
+
+	assert.Equal(t, map[interface{}]interface{}{"c": 3}, readded.Get("a").Value())
+	origins := originsOf(readded, "a")
+	require.Len(t, origins, 1)
+	assert.Equal(t, 1, origins[0].Line)
+}
+
+func TestYAMLProvider_DeleteErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		yaml string
+		err  string
+	}{
+		{"a: !delete b", `file.yaml:1:4: !delete doesn't take a value, e.g. key: !delete`},
+		{"a:\n  - 1\n  - !delete", `file.yaml:3:5: !delete can only be used for map values`},
+		{"!delete", `file.yaml:1:1: !delete can only be used for map values`},
+	}
+
+	for _, tt := range tests {
+		_, err := newYAMLProviderCoreWithParser(yamlParser{},
+			namedReader{Reader: strings.NewReader(tt.yaml), name: "file.yaml"})
+		assert.EqualError(t, err, tt.err, tt.yaml)
+	}
+}
+
+func TestValue_IsNull(t *testing.T) {
+	t.Parallel()
+
+	p := NewYAMLProviderFromBytes([]byte("nothing: ~\nempty:\nzero: 0\ndeleted: 1"), []byte("deleted: !delete"))
+
+	assert.True(t, p.Get("nothing").IsNull())
+	assert.True(t, p.Get("empty").IsNull())
+	assert.False(t, p.Get("zero").IsNull())
+	assert.False(t, p.Get("missing").IsNull())
+	assert.False(t, p.Get("deleted").IsNull())
+	assert.False(t, p.Get("missing").WithDefault(1).IsNull())
+	assert.True(t, p.Get("missing").WithDefault(1).IsDefault())
+	assert.False(t, p.Get("zero").WithDefault(0).IsDefault())
+}